@@ -0,0 +1,123 @@
+/**
+ * clone.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"reflect"
+)
+
+// Clone generates a copy of the localization map, with the same
+// global name and formatting options. The data map is copied
+// deeply, so nested maps and slices can be modified on the clone
+// without affecting the original. Pointers and structs are
+// localized by value, and are still shared.
+func (l *Map) Clone() *Map {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	c := &Map{
+		globalName:     l.globalName,
+		strict:         l.strict,
+		strictKeys:     l.strictKeys,
+		maxDepth:       l.maxDepth,
+		timeFormat:     l.timeFormat,
+		timeLayout:     l.timeLayout,
+		durationFormat: l.durationFormat,
+		bigFormat:      l.bigFormat,
+		floatPolicy:    l.floatPolicy,
+		intPolicy:      l.intPolicy,
+		stringers:      l.stringers,
+		runes:          l.runes,
+		keyCase:        l.keyCase,
+		pretty:         l.pretty,
+		prefix:         l.prefix,
+		indent:         l.indent,
+		minify:         l.minify,
+		newline:        l.newline,
+		noEscapeHTML:   l.noEscapeHTML,
+		declaration:    l.declaration,
+		globalObject:   l.globalObject,
+		bracketed:      l.bracketed,
+		callback:       l.callback,
+		freeze:         l.freeze,
+		omitSemicolon:  l.omitSemicolon,
+		scriptType:     l.scriptType,
+	}
+	if nil != l.handlers {
+		c.handlers = make(map[reflect.Type]Handler, len(l.handlers))
+		for t, fn := range l.handlers {
+			c.handlers[t] = fn
+		}
+	}
+	if nil != l.keyRenderers {
+		c.keyRenderers = make(map[string]KeyRenderer, len(l.keyRenderers))
+		for key, fn := range l.keyRenderers {
+			c.keyRenderers[key] = fn
+		}
+	}
+	if nil != l.data {
+		copies := make(map[visit]reflect.Value)
+		c.data = copyValue(reflect.ValueOf(l.data), copies).Interface().(Data)
+	}
+	return c
+}
+
+// copyValue deeply copies the maps, slices, and arrays of the
+// target, unwrapping interfaces along the way. Any other value
+// is returned as it is. The copies are recorded by the map or
+// slice they were made from, so that data referring to itself
+// is copied into data referring to the copy, rather than
+// forever.
+func copyValue(target reflect.Value, copies map[visit]reflect.Value) reflect.Value {
+	switch target.Kind() {
+	case reflect.Interface:
+		if target.IsNil() {
+			return target
+		}
+		c := reflect.New(target.Type()).Elem()
+		c.Set(copyValue(target.Elem(), copies))
+		return c
+	case reflect.Map:
+		if target.IsNil() {
+			return target
+		}
+		v := visit{target.Pointer(), target.Type(), 0}
+		if c, ok := copies[v]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(target.Type(), target.Len())
+		copies[v] = c
+		iter := target.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), copyValue(iter.Value(), copies))
+		}
+		return c
+	case reflect.Slice:
+		if target.IsNil() {
+			return target
+		}
+		v := visit{target.Pointer(), target.Type(), target.Len()}
+		if c, ok := copies[v]; ok {
+			return c
+		}
+		c := reflect.MakeSlice(target.Type(), target.Len(), target.Len())
+		copies[v] = c
+		for i := 0; i < target.Len(); i++ {
+			c.Index(i).Set(copyValue(target.Index(i), copies))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(target.Type()).Elem()
+		for i := 0; i < target.Len(); i++ {
+			c.Index(i).Set(copyValue(target.Index(i), copies))
+		}
+		return c
+	}
+	return target
+}
@@ -0,0 +1,87 @@
+/**
+ * combine.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// ErrDuplicateGlobalName indicates that several localizers would
+// assign their data to the same global variable.
+var ErrDuplicateGlobalName = fmt.Errorf("Duplicate global name provided")
+
+// CombineJS joins the JavaScript of several localizers into one
+// block, in order, with each assignment on its own line, broken
+// by the newline style of the map before it. This
+// suits pages that need several independent globals in a single
+// script element. Localizers that assign to the same target would
+// overwrite each other's data, or fail to load in the case of two
+// default exports, so they're rejected with an error wrapping
+// ErrDuplicateGlobalName, naming the target. Maps are compared by
+// what they assign, taking their declaration and global object
+// into account, while maps that pass their data to a callback
+// assign nothing. Other localizers are compared by global name.
+func CombineJS(maps ...Localizer) (template.JS, error) {
+	seen := make(map[string]bool, len(maps))
+	var b strings.Builder
+	for i, m := range maps {
+		target := assignedTarget(m)
+		if seen[target] {
+			return "", fmt.Errorf("%w: %q", ErrDuplicateGlobalName, target)
+		}
+		if "" != target {
+			seen[target] = true
+		}
+		if 0 < i {
+			b.WriteString(newlineOf(maps[i-1]))
+		}
+		b.WriteString(string(m.JS()))
+	}
+	return template.JS(b.String()), nil
+}
+
+// newlineOf returns the line break used by the localizer, which
+// is a line feed unless it's a map with another newline style.
+func newlineOf(m Localizer) string {
+	if l, ok := m.(*Map); ok {
+		return l.GetNewline().String()
+	}
+	return NewlineLF.String()
+}
+
+// assignedTarget describes what the localizer assigns its data
+// to, such as "module.exports" or `window["appData"]`, or returns
+// an empty string if it assigns nothing.
+func assignedTarget(m Localizer) string {
+	l, ok := m.(*Map)
+	if !ok {
+		return m.GetGlobalName()
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	switch {
+	case "" != l.callback:
+		return ""
+	case DeclarationExportDefault == l.declaration:
+		return "export default"
+	case DeclarationCommonJS == l.declaration:
+		return "module.exports"
+	case "" == l.globalObject:
+		return l.globalName
+	case l.bracketed || !strings.Contains(l.globalName, "."):
+		// Dot notation and computed properties are the same
+		// assignment, as long as the name is a single property.
+		return l.globalObject + "[" + quote(l.globalName) + "]"
+	}
+	return l.globalObject + "." + l.globalName
+}
@@ -0,0 +1,286 @@
+/**
+ * declaration.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"fmt"
+)
+
+// Declaration describes the keyword used to declare the
+// variable that receives the localized data.
+type Declaration int
+
+const (
+	// DeclarationNone assigns the data without declaring the
+	// variable, e.g. `_localData = {...};`. This is the default.
+	DeclarationNone Declaration = iota
+
+	// DeclarationVar declares the variable with "var".
+	DeclarationVar
+
+	// DeclarationLet declares the variable with "let".
+	DeclarationLet
+
+	// DeclarationConst declares the variable with "const".
+	DeclarationConst
+
+	// DeclarationExportConst declares the variable as a named
+	// export of an ES module, e.g.
+	// `export const _localData = {...};`.
+	DeclarationExportConst
+
+	// DeclarationExportDefault makes the data the default export
+	// of an ES module, e.g. `export default {...};`. The global
+	// variable name is left out, and isn't validated.
+	DeclarationExportDefault
+
+	// DeclarationCommonJS makes the data the export of a
+	// CommonJS module, e.g. `module.exports = {...};`, so that it
+	// can be required by Node. The global variable name is left
+	// out, and isn't validated.
+	DeclarationCommonJS
+)
+
+var (
+	ErrInvalidDeclaration = fmt.Errorf("Invalid declaration provided")
+
+	// ErrIncompatibleOptions indicates that an option can't be
+	// combined with another option that's already set.
+	ErrIncompatibleOptions = fmt.Errorf("Incompatible options provided")
+)
+
+// keyword retrieves the JavaScript keyword of the declaration.
+func (d Declaration) keyword() string {
+	switch d {
+	case DeclarationVar:
+		return "var"
+	case DeclarationLet:
+		return "let"
+	case DeclarationConst:
+		return "const"
+	case DeclarationExportConst:
+		return "export const"
+	case DeclarationExportDefault:
+		return "export default"
+	}
+	return ""
+}
+
+// named reports whether the declaration assigns the data to the
+// global variable name. Module exports which don't need a name
+// bypass its validation.
+func (d Declaration) named() bool {
+	return DeclarationExportDefault != d && DeclarationCommonJS != d
+}
+
+// SetDeclaration assigns the keyword used to declare the
+// localization map's global variable. Declaring the variable
+// keeps linters from flagging an implicit global. A property of
+// an object can't be declared, so declarations other than
+// DeclarationNone can't be combined with SetGlobalObject or a
+// dotted global name.
+//
+// Since DeclarationExportDefault and DeclarationCommonJS don't
+// validate the global variable name, switching away from them
+// fails if the current name isn't a valid variable name.
+func (l *Map) SetDeclaration(declaration Declaration) error {
+	switch declaration {
+	case DeclarationNone, DeclarationVar, DeclarationLet, DeclarationConst:
+	case DeclarationExportConst, DeclarationExportDefault, DeclarationCommonJS:
+	default:
+		return ErrInvalidDeclaration
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if DeclarationNone != declaration && ("" != l.globalObject || "" != l.callback) {
+		return ErrIncompatibleOptions
+	}
+	// A bracketed name is a property key rather than a variable
+	// name, and is only compatible with DeclarationNone anyway.
+	if !l.bracketed {
+		if err := validateGlobalName(l.globalName, declaration); nil != err {
+			return err
+		}
+	}
+	l.declaration = declaration
+	return nil
+}
+
+// GetDeclaration retrieves the keyword used to declare the
+// localization map's global variable.
+func (l *Map) GetDeclaration() Declaration {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.declaration
+}
+
+// SetGlobalObject assigns the object that receives the global
+// variable as a property, e.g. "window" produces
+// `window._localData = {...};`. Assigning the property
+// explicitly avoids strict mode's "assignment to undeclared
+// variable" errors. Use "globalThis" or "self" for workers, or
+// an empty string to go back to a bare assignment.
+func (l *Map) SetGlobalObject(object string) error {
+	if "" != object {
+		if err := validateIdentifier(object); nil != err {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if "" != object && (DeclarationNone != l.declaration || "" != l.callback) {
+		return ErrIncompatibleOptions
+	}
+	l.globalObject = object
+	return nil
+}
+
+// SetGlobalNameBracketed assigns the localization map's global
+// name as a computed property of the object, e.g. "window" and
+// "app-data" produce `window["app-data"] = {...};`. The key is
+// escaped as a string literal rather than validated, so it
+// needn't be a valid variable name. Like SetGlobalObject, this
+// can't be combined with a declaration other than
+// DeclarationNone. Calling SetGlobalName afterwards goes back to
+// dot notation.
+func (l *Map) SetGlobalNameBracketed(object, key string) error {
+	if err := validateGlobalName(object, DeclarationNone); nil != err {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if DeclarationNone != l.declaration || "" != l.callback {
+		return ErrIncompatibleOptions
+	}
+	l.globalObject = object
+	l.globalName = key
+	l.bracketed = true
+	return nil
+}
+
+// GetGlobalObject retrieves the object that receives the global
+// variable as a property, if any.
+func (l *Map) GetGlobalObject() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.globalObject
+}
+
+// SetCallback causes the data to be passed to the named function,
+// e.g. `myCallback({...});`, rather than assigned to the global
+// variable, as JSONP responses do. The callback is validated like
+// a global name, so it may be a dotted path, such as
+// "jQuery.callback". The callback can't be combined with a
+// declaration other than DeclarationNone, or with a global
+// object. An empty callback goes back to the assignment.
+func (l *Map) SetCallback(callback string) error {
+	if "" != callback {
+		if err := validateGlobalName(callback, DeclarationNone); nil != err {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if "" != callback && (DeclarationNone != l.declaration || "" != l.globalObject) {
+		return ErrIncompatibleOptions
+	}
+	l.callback = callback
+	return nil
+}
+
+// GetCallback retrieves the function that the data is passed
+// to, if any.
+func (l *Map) GetCallback() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.callback
+}
+
+// SetFreeze toggles the wrapping of the data in Object.freeze,
+// e.g. `const _localData = Object.freeze({...});`, which keeps
+// front-end scripts from accidentally modifying it. It pairs
+// naturally with DeclarationConst, which keeps the variable from
+// being reassigned. The freeze is shallow, so nested objects and
+// arrays can still be modified.
+func (l *Map) SetFreeze(freeze bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.freeze = freeze
+}
+
+// GetFreeze reports whether the data is wrapped in Object.freeze.
+func (l *Map) GetFreeze() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.freeze
+}
+
+// SetOmitSemicolon toggles the trailing semicolon, which ends the
+// output by default. Leaving it out allows the output to be
+// inlined into a larger expression, e.g. `_localData = {...}`
+// rather than `_localData = {...};`.
+func (l *Map) SetOmitSemicolon(omit bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.omitSemicolon = omit
+}
+
+// GetOmitSemicolon reports whether the trailing semicolon is left
+// out of the output.
+func (l *Map) GetOmitSemicolon() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.omitSemicolon
+}
+
+// assignment formats the head of the output, which assigns the
+// data to the global variable, e.g. "const _localData = ". The
+// caller must hold the lock.
+func (l *Map) assignment(minify bool) string {
+	switch l.declaration {
+	case DeclarationExportDefault:
+		// The space is kept even when minified, since the data may
+		// be wrapped in Object.freeze.
+		return l.declaration.keyword() + " "
+	case DeclarationCommonJS:
+		if minify {
+			return "module.exports="
+		}
+		return "module.exports = "
+	}
+
+	head := l.globalName
+	if l.bracketed {
+		head = l.globalObject + "[" + quote(head) + "]"
+	} else if "" != l.globalObject {
+		head = l.globalObject + "." + head
+	}
+	if keyword := l.declaration.keyword(); "" != keyword {
+		head = keyword + " " + head
+	}
+	if minify {
+		return head + "="
+	}
+	return head + " = "
+}
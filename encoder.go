@@ -0,0 +1,1177 @@
+/**
+ * encoder.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	numberType   = reflect.TypeOf(json.Number(""))
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	runeType     = reflect.TypeOf(rune(0))
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	urlType      = reflect.TypeOf(url.URL{})
+
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// encoder holds the state of a single pass over a target, as
+// it's written to the writer by ReflectTarget.
+type encoder struct {
+	w io.Writer
+
+	// n holds the number of bytes written so far.
+	n int
+
+	// werr holds the first error returned by the writer, after
+	// which nothing else is written.
+	werr error
+
+	// strict causes the output to be written as strict JSON,
+	// without trailing commas.
+	strict bool
+
+	// pretty causes the output to be indented, with each line
+	// starting with the prefix, followed by one copy of the
+	// indent per level of nesting.
+	pretty bool
+	prefix string
+	indent string
+
+	// minify causes all insignificant whitespace to be left out
+	// of the output.
+	minify bool
+
+	// newline holds the line break that starts each new line.
+	newline Newline
+
+	// noEscapeHTML causes the HTML-significant characters of
+	// strings to be written as they are.
+	noEscapeHTML bool
+
+	// depth holds the nesting level of the current target.
+	depth int
+
+	// maxDepth limits the nesting level, unless it's zero.
+	maxDepth int
+
+	// timeFormat and timeLayout determine how time.Time values
+	// are written.
+	timeFormat TimeFormat
+	timeLayout string
+
+	// durationFormat determines how time.Duration values are
+	// written.
+	durationFormat DurationFormat
+
+	// bigFormat determines how big.Int and big.Float values are
+	// written.
+	bigFormat BigFormat
+
+	// floatPolicy determines how NaN and ±Inf float values are
+	// written.
+	floatPolicy FloatPolicy
+
+	// intPolicy determines how integers outside of JavaScript's
+	// safe range are written.
+	intPolicy IntPolicy
+
+	// stringers causes values that implement fmt.Stringer to be
+	// written as their String() label.
+	stringers bool
+
+	// runes causes rune values to be written as characters.
+	runes bool
+
+	// keyCase determines how the Go names of struct fields are
+	// converted to keys.
+	keyCase KeyCase
+
+	// handlers holds the custom renderers of registered types.
+	handlers map[reflect.Type]Handler
+
+	// keyRenderers holds the custom renderers of the entries of
+	// the outermost object, which is the data map. They're
+	// cleared once it's started.
+	keyRenderers map[string]KeyRenderer
+
+	// path holds the keys, field names, and indices leading to
+	// the current target.
+	path []string
+
+	// err holds the first problem encountered while reflecting
+	// over the target.
+	err error
+
+	// ctx, when set, is checked periodically, and the output is
+	// abandoned once it's done.
+	ctx context.Context
+
+	// visits counts the values reflected over so far.
+	visits int
+
+	// visiting holds the pointers, maps, and slices enclosing the
+	// current target, which would be revisited by a cycle.
+	visiting map[visit]struct{}
+}
+
+// visit identifies a pointer, map, or slice by the memory it
+// refers to. Slices can share memory with different lengths, so
+// the length is part of the identity.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// ctxInterval is the number of values reflected over between
+// checks of the context.
+const ctxInterval = 64
+
+// write writes a piece of the output to the writer.
+func (e *encoder) write(s string) {
+	if nil != e.werr {
+		return
+	}
+	n, err := io.WriteString(e.w, s)
+	e.n += n
+	e.werr = err
+}
+
+// error retrieves the first problem encountered while writing
+// the output. Writer errors take precedence, since they mean
+// that the output is incomplete.
+func (e *encoder) error() error {
+	if nil != e.werr {
+		return e.werr
+	}
+	return e.err
+}
+
+// done reports whether the output has been abandoned, either
+// because the writer failed or the context is done. The context
+// is only checked periodically, to keep the overhead low. Once
+// done, nothing else is written, and the context's error is
+// reported.
+func (e *encoder) done() bool {
+	if nil != e.werr {
+		return true
+	}
+	if nil == e.ctx {
+		return false
+	}
+	if 0 == e.visits%ctxInterval {
+		e.werr = e.ctx.Err()
+	}
+	e.visits++
+	return nil != e.werr
+}
+
+// enter marks the pointer, map, or slice target as enclosing the
+// values below it, and reports whether it wasn't already. A
+// target that's already enclosing is part of a cycle, which is
+// reported and replaced with null.
+func (e *encoder) enter(target reflect.Value) bool {
+	v := visit{target.Pointer(), target.Type(), 0}
+	if reflect.Slice == target.Kind() {
+		v.len = target.Len()
+	}
+	if _, ok := e.visiting[v]; ok {
+		e.failNull(fmt.Errorf("%w at key, %v", ErrCyclicData, e.keyPath()))
+		return false
+	}
+	if nil == e.visiting {
+		e.visiting = make(map[visit]struct{})
+	}
+	e.visiting[v] = struct{}{}
+	return true
+}
+
+// leave removes the mark placed by enter, once the values below
+// the target have been written.
+func (e *encoder) leave(target reflect.Value) {
+	v := visit{target.Pointer(), target.Type(), 0}
+	if reflect.Slice == target.Kind() {
+		v.len = target.Len()
+	}
+	delete(e.visiting, v)
+}
+
+// push appends a key to the current path.
+func (e *encoder) push(key string) {
+	e.path = append(e.path, key)
+}
+
+// pushIndex appends an element index to the current path.
+func (e *encoder) pushIndex(i int) {
+	e.path = append(e.path, fmt.Sprintf("[%d]", i))
+}
+
+// pop removes the last key or index from the current path.
+func (e *encoder) pop() {
+	e.path = e.path[:len(e.path)-1]
+}
+
+// keyPath formats the current path, e.g. "nonce.login" or
+// "items[2].name".
+func (e *encoder) keyPath() string {
+	var b strings.Builder
+	for i, key := range e.path {
+		if 0 < i && !strings.HasPrefix(key, "[") {
+			b.WriteString(".")
+		}
+		b.WriteString(key)
+	}
+	return b.String()
+}
+
+// fail records a problem with the current target, unless an
+// earlier problem has already been recorded.
+func (e *encoder) fail(err error) {
+	if nil == e.err {
+		e.err = err
+	}
+}
+
+// failNull records the error, and writes null in place of the
+// value that failed, so that the output stays parseable.
+func (e *encoder) failNull(err error) {
+	e.fail(err)
+	e.write("null")
+}
+
+// beginElement starts the element of an enclosing type at index
+// i. In strict mode, elements are separated by commas.
+func (e *encoder) beginElement(i int, object bool) {
+	if e.strict && 0 < i {
+		e.write(",")
+	}
+	e.lineBreak(object)
+}
+
+// endElement ends the element of an enclosing type. Outside of
+// strict mode, every element carries a trailing comma.
+func (e *encoder) endElement() {
+	if !e.strict {
+		e.write(",")
+	}
+}
+
+// lineBreak starts a new line at the current depth. Objects put
+// each of their entries on a separate line, while arrays only do
+// so when the output is indented. Minified output is kept on a
+// single line.
+func (e *encoder) lineBreak(object bool) {
+	if e.minify || (!object && !e.pretty) {
+		return
+	}
+	e.write(e.newline.String() + e.prefix + strings.Repeat(e.indent, e.depth))
+}
+
+// writeJSON writes a non-enclosing value as encoded by the
+// encoding/json package, which takes care of number formatting
+// and string escaping.
+func (e *encoder) writeJSON(v interface{}) {
+	b, err := e.marshal(v)
+	if nil != err {
+		e.failNull(fmt.Errorf(
+			"Failed to localize value at key, %v, err: %w",
+			e.keyPath(),
+			err,
+		))
+		return
+	}
+
+	e.write(string(b))
+}
+
+// marshal encodes the value with the encoding/json package. The
+// HTML-significant characters of strings are escaped, unless
+// noEscapeHTML is set.
+func (e *encoder) marshal(v interface{}) ([]byte, error) {
+	if !e.noEscapeHTML {
+		return json.Marshal(v)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); nil != err {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// quote formats a key as an escaped string literal.
+func quote(key string) string {
+	b, _ := json.Marshal(key)
+	return string(b)
+}
+
+// quote formats a key as a string literal, escaped in the same
+// way as string values.
+func (e *encoder) quote(key string) string {
+	b, _ := e.marshal(key)
+	return string(b)
+}
+
+// writeHandler writes the target as rendered by a registered
+// handler.
+func (e *encoder) writeHandler(fn Handler, target reflect.Value) {
+	s, err := fn(target)
+	if nil != err {
+		e.failNull(fmt.Errorf(
+			"Failed to localize value at key, %v, err: %w",
+			e.keyPath(),
+			err,
+		))
+		return
+	}
+
+	e.write(s)
+}
+
+// writeTime writes a time.Time value according to the time
+// format.
+func (e *encoder) writeTime(t time.Time) {
+	switch e.timeFormat {
+	case TimeFormatUnixMilli:
+		e.writeJSON(t.UnixMilli())
+	case TimeFormatLayout:
+		e.writeJSON(t.Format(e.timeLayout))
+	default:
+		e.writeJSON(t.Format(time.RFC3339Nano))
+	}
+}
+
+// writeDuration writes a time.Duration value according to the
+// duration format.
+func (e *encoder) writeDuration(d time.Duration) {
+	switch e.durationFormat {
+	case DurationFormatString:
+		e.writeJSON(d.String())
+	case DurationFormatMilli:
+		e.writeJSON(float64(d) / float64(time.Millisecond))
+	default:
+		e.writeJSON(int64(d))
+	}
+}
+
+// writeFloat writes a float value with the given bit size,
+// applying the float policy to NaN and ±Inf.
+func (e *encoder) writeFloat(f float64, bitSize int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		switch {
+		case FloatPolicyNull == e.floatPolicy:
+			e.write("null")
+		case FloatPolicyLiteral == e.floatPolicy && !e.strict:
+			switch {
+			case math.IsNaN(f):
+				e.write("NaN")
+			case 0 < f:
+				e.write("Infinity")
+			default:
+				e.write("-Infinity")
+			}
+		default:
+			e.failNull(fmt.Errorf(
+				"%w, %v, at key, %v",
+				ErrNonFiniteFloat,
+				f,
+				e.keyPath(),
+			))
+		}
+		return
+	}
+
+	// Keeps the 32-bit precision, so that float32(0.1) isn't
+	// widened to 0.10000000149011612.
+	if 32 == bitSize {
+		e.writeJSON(float32(f))
+		return
+	}
+	e.writeJSON(f)
+}
+
+// writeInt writes the digits of an integer, applying the integer
+// policy to integers outside of JavaScript's safe range.
+func (e *encoder) writeInt(digits string, safe bool) {
+	switch {
+	case safe || IntPolicyNumber == e.intPolicy:
+		e.write(digits)
+	case IntPolicyBigInt == e.intPolicy && !e.strict:
+		e.write(digits + "n")
+	default:
+		e.writeJSON(digits)
+	}
+}
+
+// writeNumber writes a json.Number, such as one decoded with
+// UseNumber, as the number literal that it holds, rather than as
+// a string. Integers are subject to the integer policy, while
+// anything that isn't a valid number is reported and replaced
+// with null by encoding/json.
+func (e *encoder) writeNumber(n json.Number) {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		i, err := strconv.ParseInt(s, 10, 64)
+		if nil == err {
+			e.writeInt(s, -maxSafeInt <= i && i <= maxSafeInt)
+			return
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			e.writeInt(s, false)
+			return
+		}
+	}
+	e.writeJSON(n)
+}
+
+// writeBig writes a big.Int or big.Float value, or a pointer to
+// one, according to the big number format, and reports whether
+// the target was one of them. Their internals are unexported, so
+// they can't be reflected over like other structs.
+func (e *encoder) writeBig(target reflect.Value) bool {
+	if reflect.Ptr == target.Kind() && !target.IsNil() {
+		target = target.Elem()
+	}
+
+	var digits string
+	switch target.Type() {
+	case bigIntType:
+		x := target.Interface().(big.Int)
+		digits = x.String()
+	case bigFloatType:
+		x := target.Interface().(big.Float)
+		if x.IsInf() {
+			e.failNull(fmt.Errorf(
+				"Failed to localize infinite big.Float at key, %v",
+				e.keyPath(),
+			))
+			return true
+		}
+		digits = x.Text('g', -1)
+	default:
+		return false
+	}
+
+	if BigFormatString == e.bigFormat {
+		e.writeJSON(digits)
+	} else {
+		e.write(digits)
+	}
+	return true
+}
+
+// isStringer reports whether the target can be written as its
+// fmt.Stringer label. Interfaces are unwrapped first, and nil
+// pointers are written as null, rather than risking a panic in
+// String().
+func isStringer(target reflect.Value) bool {
+	switch target.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Ptr:
+		if target.IsNil() {
+			return false
+		}
+	}
+	return target.CanInterface() && target.Type().Implements(stringerType)
+}
+
+// isMarshaler reports whether the target can be written as its
+// json.Marshaler output. Like isStringer, nil pointers are left
+// to be written as null.
+func isMarshaler(target reflect.Value) bool {
+	switch target.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Ptr:
+		if target.IsNil() {
+			return false
+		}
+	}
+	return target.CanInterface() && target.Type().Implements(marshalerType)
+}
+
+// writeMarshaler writes the output of a json.Marshaler, compacted
+// onto one line. The output is validated, and its strings are
+// escaped in the same way as string values.
+func (e *encoder) writeMarshaler(m json.Marshaler) {
+	b, err := m.MarshalJSON()
+	if nil != err {
+		e.failNull(fmt.Errorf(
+			"Failed to localize value at key, %v, err: %w",
+			e.keyPath(),
+			err,
+		))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); nil != err {
+		e.failNull(fmt.Errorf(
+			"Failed to localize value at key, %v, err: %w",
+			e.keyPath(),
+			err,
+		))
+		return
+	}
+	out := buf.String()
+	if !e.noEscapeHTML {
+		var escaped bytes.Buffer
+		json.HTMLEscape(&escaped, buf.Bytes())
+		out = escaped.String()
+	}
+
+	// Line and paragraph separators are escaped regardless, as
+	// encoding/json does, since older JavaScript engines don't
+	// allow them in string literals.
+	e.write(lineSeparatorReplacer.Replace(out))
+}
+
+// lineSeparatorReplacer escapes the U+2028 and U+2029 characters.
+var lineSeparatorReplacer = strings.NewReplacer("\u2028", `\u2028`, "\u2029", `\u2029`)
+
+// isTextMarshaler reports whether the target can be written as
+// its encoding.TextMarshaler text, which takes precedence over
+// reflecting over the target. Like encoding/json, a
+// json.Marshaler takes precedence over it in turn. Like
+// isStringer, nil pointers are left to be written as null.
+func isTextMarshaler(target reflect.Value) bool {
+	switch target.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Ptr:
+		if target.IsNil() {
+			return false
+		}
+	}
+	return target.CanInterface() && target.Type().Implements(textMarshalerType)
+}
+
+// writeText writes the text of an encoding.TextMarshaler as a
+// string.
+func (e *encoder) writeText(m encoding.TextMarshaler) {
+	text, err := m.MarshalText()
+	if nil != err {
+		e.failNull(fmt.Errorf(
+			"Failed to localize value at key, %v, err: %w",
+			e.keyPath(),
+			err,
+		))
+		return
+	}
+
+	e.writeJSON(string(text))
+}
+
+// field is a struct field or map entry that's due to be
+// localized.
+type field struct {
+	name  string
+	value reflect.Value
+
+	// quoted causes the value to be written as a string, as by
+	// the "string" struct tag option.
+	quoted bool
+}
+
+// structFields gathers the fields of the target struct that are
+// to be localized. Fields are named by their "json" struct tag
+// when one is present. Unexported fields and fields tagged with
+// `json:"-"` are skipped, matching encoding/json, so that private
+// state isn't leaked to the browser. Fields tagged with the
+// "omitempty" option are skipped when they hold an empty value,
+// and those with the "string" option hold their value as a
+// string, e.g. "9007199254740993" rather than 9007199254740993.
+// The fields of embedded structs without a tag name are
+// flattened into the parent, following the same rules as
+// encoding/json when names collide. Fields without a tag name are
+// named by their Go name, converted to the key case.
+func structFields(target reflect.Value, keyCase KeyCase) []field {
+	var candidates []embeddedField
+	visited := map[reflect.Type]bool{target.Type(): true}
+	collectFields(target, 0, keyCase, visited, &candidates)
+
+	// The shallowest field with a name wins. Among fields at the
+	// same depth, a tagged one wins, and otherwise the name is
+	// ambiguous and all of them are left out.
+	best := make(map[string]int, len(candidates))
+	ambiguous := make(map[string]bool)
+	for i, c := range candidates {
+		j, ok := best[c.name]
+		switch {
+		case !ok || c.depth < candidates[j].depth:
+			best[c.name] = i
+			delete(ambiguous, c.name)
+		case c.depth == candidates[j].depth && c.tagged != candidates[j].tagged:
+			if c.tagged {
+				best[c.name] = i
+				delete(ambiguous, c.name)
+			}
+		case c.depth == candidates[j].depth:
+			ambiguous[c.name] = true
+		}
+	}
+
+	fields := make([]field, 0, len(best))
+	for i, c := range candidates {
+		if best[c.name] != i || ambiguous[c.name] {
+			continue
+		}
+		fields = append(fields, c.field)
+	}
+	return fields
+}
+
+// embeddedField is a struct field that may have been promoted
+// from an embedded struct.
+type embeddedField struct {
+	field
+
+	// depth holds the embedding level of the field.
+	depth int
+
+	// tagged reports whether the field is named by its tag.
+	tagged bool
+}
+
+// collectFields appends the fields of the target struct to the
+// candidates, flattening embedded structs along the way. The
+// visited types are those being flattened on the way down, so
+// that a struct embedding itself through a pointer is only
+// flattened once, since its deeper fields would be hidden by
+// the shallower ones anyway.
+func collectFields(target reflect.Value, depth int, keyCase KeyCase, visited map[reflect.Type]bool, candidates *[]embeddedField) {
+	targetType := target.Type()
+	for i := 0; i < targetType.NumField(); i++ {
+		structField := targetType.Field(i)
+		tag := structField.Tag.Get("json")
+		if "-" == tag {
+			continue
+		}
+		tagName, opts, _ := strings.Cut(tag, ",")
+		value := target.Field(i)
+
+		// Embedded structs are flattened, even when their type
+		// is unexported, since their own fields may be exported.
+		if structField.Anonymous && "" == tagName {
+			embedded := value
+			if reflect.Ptr == embedded.Kind() {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if reflect.Struct == embedded.Kind() {
+				if !visited[embedded.Type()] {
+					visited[embedded.Type()] = true
+					collectFields(embedded, depth+1, keyCase, visited, candidates)
+					delete(visited, embedded.Type())
+				}
+				continue
+			}
+		}
+		if "" != structField.PkgPath {
+			continue
+		}
+
+		name := keyCase.convert(structField.Name)
+		if "" != tagName {
+			name = tagName
+		}
+		if hasTagOption(opts, "omitempty") && isEmptyValue(value) {
+			continue
+		}
+		*candidates = append(*candidates, embeddedField{
+			field: field{
+				name:   name,
+				value:  value,
+				quoted: hasTagOption(opts, "string"),
+			},
+			depth:  depth,
+			tagged: "" != tagName,
+		})
+	}
+}
+
+// mapEntries gathers the entries of the target map, sorted by
+// key so that the output is the same from one run to the next.
+// Object keys are always strings in JavaScript, so integer and
+// boolean keys are converted to their string representation, as
+// encoding/json does. Interface keys are converted by their
+// concrete value. Integer keys are sorted by their value, so
+// that 2 comes before 10, and the other keys are sorted by their
+// string representation. Entries with any other kind of key,
+// such as a struct, are left out and reported, as are entries
+// whose keys convert to the same string, such as the interface
+// keys 1 and "1".
+func (e *encoder) mapEntries(target reflect.Value) []field {
+	keys := target.MapKeys()
+	for i, keyValue := range keys {
+		// Interface keys, such as those decoded from YAML, are
+		// converted by their concrete value.
+		if reflect.Interface == keyValue.Kind() && !keyValue.IsNil() {
+			keys[i] = keyValue.Elem()
+		}
+	}
+	numeric := sortIntKeys(keys)
+	entries := make([]field, 0, len(keys))
+	counts := make(map[string]int, len(keys))
+	for _, keyValue := range keys {
+		key, ok := mapKey(keyValue)
+		if !ok {
+			e.fail(fmt.Errorf(
+				"%w of map key, %v, at key, %v",
+				ErrUnsupportedKind,
+				keyValue.Kind(),
+				e.keyPath(),
+			))
+			continue
+		}
+		counts[key]++
+		entries = append(entries, field{
+			name:  key,
+			value: target.MapIndex(keyValue),
+		})
+	}
+	if !numeric {
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].name < entries[j].name
+		})
+	}
+
+	unique := entries[:0]
+	for _, entry := range entries {
+		switch counts[entry.name] {
+		case 0:
+		case 1:
+			unique = append(unique, entry)
+		default:
+			e.fail(fmt.Errorf(
+				"%w of map key, %q, at key, %v",
+				ErrDuplicateKey,
+				entry.name,
+				e.keyPath(),
+			))
+			counts[entry.name] = 0
+		}
+	}
+	return unique
+}
+
+// sortIntKeys sorts integer map keys by their value, and reports
+// whether the keys were integers. Signed and unsigned keys, such
+// as the concrete values of interface keys, may be mixed.
+func sortIntKeys(keys []reflect.Value) bool {
+	if 0 == len(keys) {
+		return false
+	}
+	for _, key := range keys {
+		if !isInt(key) && !isUint(key) {
+			return false
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		aNeg := isInt(a) && 0 > a.Int()
+		bNeg := isInt(b) && 0 > b.Int()
+		switch {
+		case aNeg != bNeg:
+			return aNeg
+		case aNeg:
+			return a.Int() < b.Int()
+		}
+		return uintValue(a) < uintValue(b)
+	})
+	return true
+}
+
+// isInt reports whether the value is a signed integer.
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// isUint reports whether the value is an unsigned integer.
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// uintValue returns the value of a non-negative integer.
+func uintValue(v reflect.Value) uint64 {
+	if isInt(v) {
+		return uint64(v.Int())
+	}
+	return v.Uint()
+}
+
+// mapKey converts a map key to its string representation.
+func mapKey(key reflect.Value) (string, bool) {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), true
+	case reflect.Bool:
+		return strconv.FormatBool(key.Bool()), true
+	}
+	return "", false
+}
+
+// writeObject writes fields or map entries as an object.
+func (e *encoder) writeObject(fields []field) {
+	renderers := e.keyRenderers
+	e.keyRenderers = nil
+
+	e.writeEntries(len(fields), func(i int) string {
+		return fields[i].name
+	}, func(i int) {
+		if fn, ok := renderers[fields[i].name]; ok {
+			e.writeKeyRenderer(fn, fields[i].value)
+			return
+		}
+		if !fields[i].quoted || !e.writeQuoted(fields[i].value) {
+			e.reflect(fields[i].value)
+		}
+	})
+}
+
+// writeEntries writes n entries as an object, where each entry's
+// key is retrieved by name and its value is written by value.
+func (e *encoder) writeEntries(n int, name func(i int) string, value func(i int)) {
+	if !e.deepen() {
+		return
+	}
+	if 0 == n {
+		e.write("{}")
+		return
+	}
+	colon := ":"
+	if e.pretty && !e.minify {
+		colon = ": "
+	}
+
+	e.write("{")
+	e.depth++
+	for i := 0; i < n; i++ {
+		key := name(i)
+		e.beginElement(i, true)
+		e.push(key)
+		e.write(e.quote(key) + colon)
+		value(i)
+		e.pop()
+		e.endElement()
+	}
+	e.depth--
+	e.lineBreak(true)
+	e.write("}")
+}
+
+// writeQuoted writes a string, number, or boolean value, or a
+// pointer to one, as a string holding its JSON encoding, and
+// reports whether the target was one of them. Like encoding/json,
+// the "string" option doesn't apply to any other kind of value.
+func (e *encoder) writeQuoted(target reflect.Value) bool {
+	s, ok := quotedString(target, e.quote)
+	if ok {
+		e.writeJSON(s)
+	}
+	return ok
+}
+
+// quotedString formats a string, number, or boolean value, or a
+// pointer to one, as its JSON encoding, for the "string" option.
+// Strings are formatted by the quote function. It reports whether
+// the target was one of them.
+func quotedString(target reflect.Value, quote func(string) string) (string, bool) {
+	if reflect.Ptr == target.Kind() && !target.IsNil() {
+		target = target.Elem()
+	}
+
+	switch target.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(target.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(target.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(target.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		var v interface{} = target.Float()
+		if reflect.Float32 == target.Kind() {
+			v = float32(target.Float())
+		}
+		b, err := json.Marshal(v)
+		if nil != err {
+			return "", false
+		}
+		return string(b), true
+	case reflect.String:
+		return quote(target.String()), true
+	}
+	return "", false
+}
+
+// deepen reports whether an object or array may be nested at the
+// current depth. One that's nested too deeply is reported and
+// replaced with null.
+func (e *encoder) deepen() bool {
+	if 0 < e.maxDepth && e.maxDepth <= e.depth {
+		e.failNull(fmt.Errorf("%w, %v, at key, %v", ErrMaxDepth, e.maxDepth, e.keyPath()))
+		return false
+	}
+	return true
+}
+
+// writeArray writes the elements of a slice or array as an
+// array.
+func (e *encoder) writeArray(target reflect.Value) {
+	e.writeElements(target.Len(), func(i int) {
+		e.reflect(target.Index(i))
+	})
+}
+
+// writeElements writes n elements as an array, where each
+// element is written by value.
+func (e *encoder) writeElements(n int, value func(i int)) {
+	if !e.deepen() {
+		return
+	}
+	if 0 == n {
+		e.write("[]")
+		return
+	}
+
+	e.write("[")
+	e.depth++
+	for i := 0; i < n; i++ {
+		e.beginElement(i, false)
+		e.pushIndex(i)
+		value(i)
+		e.pop()
+		e.endElement()
+	}
+	e.depth--
+	e.lineBreak(false)
+	e.write("]")
+}
+
+// hasTagOption reports whether the comma-separated options of a
+// struct tag contain the named option.
+func hasTagOption(opts string, name string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if name == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue reports whether the value is considered empty by
+// the "omitempty" struct tag option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return 0 == v.Len()
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return 0 == v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return 0 == v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return 0 == v.Float()
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// reflect writes the JavaScript equivalent of the target to the
+// writer. See ReflectTarget for details.
+func (e *encoder) reflect(target reflect.Value) {
+	if e.done() {
+		return
+	}
+	if !target.IsValid() {
+		e.write("null")
+		return
+	}
+
+	// Registered handlers take precedence over the built-in
+	// translation.
+	if fn, ok := e.handlers[target.Type()]; ok {
+		e.writeHandler(fn, target)
+		return
+	}
+	if e.writeFast(target) {
+		return
+	}
+
+	// Some types are better represented by their meaning than
+	// by their internal structure. Pointers to them, such as the
+	// *time.Time of an optional timestamp, are unwrapped first, so
+	// that their own methods don't take precedence.
+	unwrapped := target
+	if reflect.Ptr == unwrapped.Kind() && !unwrapped.IsNil() {
+		unwrapped = unwrapped.Elem()
+	}
+	if timeType == unwrapped.Type() {
+		e.writeTime(unwrapped.Interface().(time.Time))
+		return
+	}
+	if durationType == unwrapped.Type() {
+		e.writeDuration(time.Duration(unwrapped.Int()))
+		return
+	}
+	if numberType == unwrapped.Type() {
+		e.writeNumber(json.Number(unwrapped.String()))
+		return
+	}
+	if e.writeBig(target) {
+		return
+	}
+	if isMarshaler(target) {
+		e.writeMarshaler(target.Interface().(json.Marshaler))
+		return
+	}
+	if isTextMarshaler(target) {
+		e.writeText(target.Interface().(encoding.TextMarshaler))
+		return
+	}
+
+	// url.URL only implements encoding.BinaryMarshaler, so it's
+	// special-cased to avoid reflecting over its internals.
+	if urlType == target.Type() {
+		u := target.Interface().(url.URL)
+		e.writeJSON(u.String())
+		return
+	}
+	if e.stringers && isStringer(target) {
+		e.writeJSON(target.Interface().(fmt.Stringer).String())
+		return
+	}
+	if e.runes && runeType == target.Type() {
+		e.writeJSON(string(rune(target.Int())))
+		return
+	}
+
+	targetType := target.Type().Kind().String()
+	switch targetType {
+	case "interface", "ptr":
+		// Chains of pointers and interfaces, such as **int or an
+		// interface holding a *struct, are unwrapped until a
+		// concrete value is reached. A nil at any hop is null.
+		f := target
+		for reflect.Interface == f.Kind() || reflect.Ptr == f.Kind() {
+			if f.IsNil() {
+				e.write("null")
+				return
+			}
+			if reflect.Ptr == f.Kind() {
+				if !e.enter(f) {
+					return
+				}
+				defer e.leave(f)
+			}
+			f = f.Elem()
+
+			// A pointer may carry its own MarshalJSON, MarshalText,
+			// or String method.
+			if isMarshaler(f) || isTextMarshaler(f) || (e.stringers && isStringer(f)) {
+				break
+			}
+		}
+
+		e.reflect(f)
+	case "struct":
+		e.writeObject(structFields(target, e.keyCase))
+	case "map":
+		if !target.IsNil() {
+			if !e.enter(target) {
+				return
+			}
+			defer e.leave(target)
+		}
+		e.writeObject(e.mapEntries(target))
+	case "slice", "array":
+		// Byte slices are binary blobs rather than lists, and are
+		// written as base64 strings, matching encoding/json.
+		if "slice" == targetType && reflect.Uint8 == target.Type().Elem().Kind() {
+			e.writeJSON(target.Bytes())
+			return
+		}
+
+		if "slice" == targetType && 0 < target.Len() {
+			if !e.enter(target) {
+				return
+			}
+			defer e.leave(target)
+		}
+		e.writeArray(target)
+	case "bool":
+		e.writeJSON(target.Bool())
+	case "int", "int8", "int16", "int32", "int64":
+		i := target.Int()
+		e.writeInt(strconv.FormatInt(i, 10), -maxSafeInt <= i && i <= maxSafeInt)
+	case "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		u := target.Uint()
+		e.writeInt(strconv.FormatUint(u, 10), u <= maxSafeInt)
+	case "float32":
+		e.writeFloat(target.Float(), 32)
+	case "float64":
+		e.writeFloat(target.Float(), 64)
+	case "complex64":
+		// JavaScript has no complex numbers, so they're written
+		// as [real, imag] arrays.
+		c := target.Complex()
+		e.reflect(reflect.ValueOf([2]float32{float32(real(c)), float32(imag(c))}))
+	case "complex128":
+		c := target.Complex()
+		e.reflect(reflect.ValueOf([2]float64{real(c), imag(c)}))
+	case "string":
+		e.writeJSON(target.String())
+	default:
+		e.failNull(fmt.Errorf(
+			"%w, %v, at key, %v",
+			ErrUnsupportedKind,
+			targetType,
+			e.keyPath(),
+		))
+	}
+}
@@ -0,0 +1,93 @@
+/**
+ * fast.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+var (
+	stringMapType   = reflect.TypeOf(map[string]string(nil))
+	intMapType      = reflect.TypeOf(map[string]int(nil))
+	boolMapType     = reflect.TypeOf(map[string]bool(nil))
+	stringSliceType = reflect.TypeOf([]string(nil))
+	intSliceType    = reflect.TypeOf([]int(nil))
+)
+
+// writeFast writes the common container types, such as
+// map[string]string and []int, without reflecting over each of
+// their elements, and reports whether the target was one of
+// them. Only the exact types are matched, so named types, such
+// as `type Labels map[string]string`, take the reflection path.
+// The output is the same either way. While any handlers are
+// registered, the reflection path is always taken, since they
+// may apply to the elements.
+func (e *encoder) writeFast(target reflect.Value) bool {
+	if 0 < len(e.handlers) || !target.CanInterface() {
+		return false
+	}
+
+	switch target.Type() {
+	case stringMapType:
+		m := target.Interface().(map[string]string)
+		keys := sortedKeys(m)
+		e.writeEntries(len(keys), func(i int) string {
+			return keys[i]
+		}, func(i int) {
+			e.writeJSON(m[keys[i]])
+		})
+	case intMapType:
+		m := target.Interface().(map[string]int)
+		keys := sortedKeys(m)
+		e.writeEntries(len(keys), func(i int) string {
+			return keys[i]
+		}, func(i int) {
+			e.writeFastInt(m[keys[i]])
+		})
+	case boolMapType:
+		m := target.Interface().(map[string]bool)
+		keys := sortedKeys(m)
+		e.writeEntries(len(keys), func(i int) string {
+			return keys[i]
+		}, func(i int) {
+			e.write(strconv.FormatBool(m[keys[i]]))
+		})
+	case stringSliceType:
+		s := target.Interface().([]string)
+		e.writeElements(len(s), func(i int) {
+			e.writeJSON(s[i])
+		})
+	case intSliceType:
+		s := target.Interface().([]int)
+		e.writeElements(len(s), func(i int) {
+			e.writeFastInt(s[i])
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// writeFastInt writes an int, applying the integer policy as
+// the reflection path does.
+func (e *encoder) writeFastInt(i int) {
+	e.writeInt(strconv.Itoa(i), -maxSafeInt <= int64(i) && int64(i) <= maxSafeInt)
+}
+
+// sortedKeys retrieves the keys of a map, in sorted order.
+func sortedKeys[V interface{}](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,437 @@
+/**
+ * format.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"fmt"
+)
+
+// TimeFormat describes how time.Time values are localized.
+type TimeFormat int
+
+const (
+	// TimeFormatRFC3339 localizes times as RFC 3339 strings,
+	// with sub-second precision when present. This matches
+	// encoding/json, and is the default.
+	TimeFormatRFC3339 TimeFormat = iota
+
+	// TimeFormatUnixMilli localizes times as the number of
+	// milliseconds since the Unix epoch, which can be passed
+	// directly to JavaScript's Date constructor.
+	TimeFormatUnixMilli
+
+	// TimeFormatLayout localizes times as strings, formatted
+	// with a custom time.Format layout.
+	TimeFormatLayout
+)
+
+// DurationFormat describes how time.Duration values are
+// localized.
+type DurationFormat int
+
+const (
+	// DurationFormatNano localizes durations as their number of
+	// nanoseconds. This matches encoding/json, and is the
+	// default.
+	DurationFormatNano DurationFormat = iota
+
+	// DurationFormatString localizes durations as strings, as
+	// formatted by Duration.String(), e.g. "1h30m0s".
+	DurationFormatString
+
+	// DurationFormatMilli localizes durations as their number of
+	// milliseconds, which suits JavaScript's timer functions.
+	// Fractions of a millisecond are kept.
+	DurationFormatMilli
+)
+
+// BigFormat describes how big.Int and big.Float values are
+// localized.
+type BigFormat int
+
+const (
+	// BigFormatNumber localizes big numbers as number literals
+	// with all of their digits. This is the default. JavaScript
+	// numbers are doubles, so precision is lost beyond 2^53.
+	BigFormatNumber BigFormat = iota
+
+	// BigFormatString localizes big numbers as strings of their
+	// digits, which preserves their precision.
+	BigFormatString
+)
+
+// FloatPolicy describes how the special float values, NaN and
+// ±Inf, are localized. JSON can't represent them at all.
+type FloatPolicy int
+
+const (
+	// FloatPolicyError replaces special values with null, and
+	// reports them with an error wrapping ErrNonFiniteFloat.
+	// This is the default.
+	FloatPolicyError FloatPolicy = iota
+
+	// FloatPolicyNull silently replaces special values with
+	// null, as JSON.stringify does.
+	FloatPolicyNull
+
+	// FloatPolicyLiteral localizes special values as the
+	// JavaScript NaN, Infinity, and -Infinity literals. These
+	// aren't valid JSON, so in strict mode they're handled as if
+	// by FloatPolicyError.
+	FloatPolicyLiteral
+)
+
+// IntPolicy describes how integers outside of JavaScript's safe
+// range, ±(2^53-1), are localized. JavaScript numbers are
+// doubles, which can't represent every integer beyond that
+// range, so values such as 64-bit IDs would be silently
+// corrupted.
+type IntPolicy int
+
+const (
+	// IntPolicyNumber localizes every integer as a number
+	// literal, matching encoding/json. This is the default.
+	IntPolicyNumber IntPolicy = iota
+
+	// IntPolicyString localizes integers outside of the safe
+	// range as strings of their digits, while safe integers are
+	// still localized as numbers.
+	IntPolicyString
+
+	// IntPolicyBigInt localizes integers outside of the safe
+	// range as BigInt literals, e.g. 9007199254740993n, while
+	// safe integers are still localized as numbers. BigInt
+	// literals aren't valid JSON, so this policy can't be
+	// assigned in strict mode, and if strict mode is enabled
+	// afterwards, or the data is serialized by JSON, the values
+	// are localized as if by IntPolicyString.
+	IntPolicyBigInt
+)
+
+// Newline describes the line breaks that separate the lines of
+// the output.
+type Newline int
+
+const (
+	// NewlineLF breaks lines with "\n". This is the default.
+	NewlineLF Newline = iota
+
+	// NewlineCRLF breaks lines with "\r\n", which avoids mixed
+	// line endings in scripts generated for Windows toolchains.
+	NewlineCRLF
+)
+
+// String retrieves the characters of the line break.
+func (n Newline) String() string {
+	if NewlineCRLF == n {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// maxSafeInt is the largest integer, 2^53-1, that JavaScript
+// numbers represent exactly.
+const maxSafeInt = 1<<53 - 1
+
+var (
+	ErrInvalidTimeFormat     = fmt.Errorf("Invalid time format provided")
+	ErrInvalidDurationFormat = fmt.Errorf("Invalid duration format provided")
+	ErrInvalidBigFormat      = fmt.Errorf("Invalid big number format provided")
+	ErrInvalidFloatPolicy    = fmt.Errorf("Invalid float policy provided")
+	ErrInvalidIntPolicy      = fmt.Errorf("Invalid integer policy provided")
+	ErrInvalidNewline        = fmt.Errorf("Invalid newline provided")
+
+	// ErrNonFiniteFloat indicates that a NaN or ±Inf float
+	// couldn't be localized under the float policy.
+	ErrNonFiniteFloat = fmt.Errorf("Non-finite float value")
+)
+
+// SetTimeFormat assigns the format used to localize time.Time
+// values. The layout is only used by TimeFormatLayout, and must
+// be empty otherwise.
+func (l *Map) SetTimeFormat(format TimeFormat, layout string) error {
+	switch format {
+	case TimeFormatRFC3339, TimeFormatUnixMilli:
+		if "" != layout {
+			return ErrInvalidTimeFormat
+		}
+	case TimeFormatLayout:
+		if "" == layout {
+			return ErrInvalidTimeFormat
+		}
+	default:
+		return ErrInvalidTimeFormat
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.timeFormat = format
+	l.timeLayout = layout
+	return nil
+}
+
+// GetTimeFormat retrieves the format and layout used to localize
+// time.Time values.
+func (l *Map) GetTimeFormat() (TimeFormat, string) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.timeFormat, l.timeLayout
+}
+
+// SetDurationFormat assigns the format used to localize
+// time.Duration values.
+func (l *Map) SetDurationFormat(format DurationFormat) error {
+	switch format {
+	case DurationFormatNano, DurationFormatString, DurationFormatMilli:
+	default:
+		return ErrInvalidDurationFormat
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.durationFormat = format
+	return nil
+}
+
+// GetDurationFormat retrieves the format used to localize
+// time.Duration values.
+func (l *Map) GetDurationFormat() DurationFormat {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.durationFormat
+}
+
+// SetBigFormat assigns the format used to localize big.Int and
+// big.Float values.
+func (l *Map) SetBigFormat(format BigFormat) error {
+	switch format {
+	case BigFormatNumber, BigFormatString:
+	default:
+		return ErrInvalidBigFormat
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.bigFormat = format
+	return nil
+}
+
+// GetBigFormat retrieves the format used to localize big.Int and
+// big.Float values.
+func (l *Map) GetBigFormat() BigFormat {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.bigFormat
+}
+
+// SetFloatPolicy assigns the policy used to localize NaN and
+// ±Inf float values.
+func (l *Map) SetFloatPolicy(policy FloatPolicy) error {
+	switch policy {
+	case FloatPolicyError, FloatPolicyNull, FloatPolicyLiteral:
+	default:
+		return ErrInvalidFloatPolicy
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.floatPolicy = policy
+	return nil
+}
+
+// GetFloatPolicy retrieves the policy used to localize NaN and
+// ±Inf float values.
+func (l *Map) GetFloatPolicy() FloatPolicy {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.floatPolicy
+}
+
+// SetIntPolicy assigns the policy used to localize integers
+// outside of JavaScript's safe range. IntPolicyBigInt is
+// incompatible with strict mode.
+func (l *Map) SetIntPolicy(policy IntPolicy) error {
+	switch policy {
+	case IntPolicyNumber, IntPolicyString, IntPolicyBigInt:
+	default:
+		return ErrInvalidIntPolicy
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if IntPolicyBigInt == policy && l.strict {
+		return ErrIncompatibleOptions
+	}
+	l.intPolicy = policy
+	return nil
+}
+
+// GetIntPolicy retrieves the policy used to localize integers
+// outside of JavaScript's safe range.
+func (l *Map) GetIntPolicy() IntPolicy {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.intPolicy
+}
+
+// SetStringers toggles the use of fmt.Stringer. When enabled,
+// values that implement fmt.Stringer are localized as their
+// String() label, such as an enum type's human readable name.
+// Types with a more specific representation, such as time.Time
+// and time.Duration, aren't affected.
+func (l *Map) SetStringers(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stringers = enabled
+}
+
+// GetStringers reports whether values that implement
+// fmt.Stringer are localized as their String() label.
+func (l *Map) GetStringers() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.stringers
+}
+
+// SetRunes toggles the rendering of runes as characters. When
+// enabled, rune values are localized as single-character strings,
+// e.g. 'A' as "A" rather than 65. As rune is an alias for int32,
+// the two can't be told apart, and plain int32 values are also
+// affected. Named types based on either, such as
+// `type Letter rune`, keep being localized as numbers.
+func (l *Map) SetRunes(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.runes = enabled
+}
+
+// GetRunes reports whether rune values are localized as
+// single-character strings.
+func (l *Map) GetRunes() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.runes
+}
+
+// SetIndent causes the localized data to be pretty-printed, in
+// the same way as json.MarshalIndent. Each element of an object
+// or array begins on a new line starting with the prefix,
+// followed by one copy of the indent per level of nesting.
+func (l *Map) SetIndent(prefix, indent string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pretty = true
+	l.prefix = prefix
+	l.indent = indent
+}
+
+// ClearIndent restores the default, unindented layout.
+func (l *Map) ClearIndent() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pretty = false
+	l.prefix = ""
+	l.indent = ""
+}
+
+// GetIndent retrieves the prefix and indent used to pretty-print
+// the localized data, and whether pretty-printing is enabled.
+func (l *Map) GetIndent() (prefix, indent string, ok bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.prefix, l.indent, l.pretty
+}
+
+// SetMinify toggles minification. Minified output leaves out all
+// insignificant whitespace, producing a single-line assignment
+// that keeps inline scripts as small as possible. Minification
+// takes precedence over SetIndent.
+func (l *Map) SetMinify(minify bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.minify = minify
+}
+
+// GetMinify reports whether the localized data is minified.
+func (l *Map) GetMinify() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.minify
+}
+
+// SetEscapeHTML toggles the escaping of the HTML-significant
+// characters, <, >, and &, inside of strings, which is enabled by
+// default. Disabling it keeps trusted content, such as sanitized
+// HTML snippets, readable, e.g. "<b>" rather than "\u003cb\u003e".
+// Strings are still escaped as valid string literals either way.
+//
+// Only disable escaping for content that's entirely trusted. The
+// escaping is what prevents a string such as "</script>" from
+// closing the surrounding script element, including the ones
+// rendered by Script and ScriptTag, which would allow the rest of
+// the string to be injected into the page.
+func (l *Map) SetEscapeHTML(escape bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.noEscapeHTML = !escape
+}
+
+// GetEscapeHTML reports whether the HTML-significant characters
+// inside of strings are escaped.
+func (l *Map) GetEscapeHTML() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return !l.noEscapeHTML
+}
+
+// SetNewline assigns the line breaks that separate the lines of
+// the localized data. Minified output has no line breaks at all.
+func (l *Map) SetNewline(newline Newline) error {
+	switch newline {
+	case NewlineLF, NewlineCRLF:
+	default:
+		return ErrInvalidNewline
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.newline = newline
+	return nil
+}
+
+// GetNewline retrieves the line breaks that separate the lines of
+// the localized data.
+func (l *Map) GetNewline() Newline {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.newline
+}
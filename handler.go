@@ -0,0 +1,91 @@
+/**
+ * handler.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+)
+
+// Handler renders a value of a registered type as JavaScript.
+// The returned string is written to the output as it is, so it
+// must be a complete JavaScript expression, such as a quoted
+// string literal or a number.
+type Handler func(reflect.Value) (string, error)
+
+// RegisterHandler causes values of the exact type t to be
+// rendered by fn, rather than by the built-in translation. This
+// allows types such as money, UUIDs, or decimals to be localized
+// in a project-specific way. Registering a nil fn removes the
+// handler for the type. If fn returns an error, the value is
+// replaced with null and the error is reported by JSWithError
+// and WriteJS.
+func (l *Map) RegisterHandler(t reflect.Type, fn func(reflect.Value) (string, error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if nil == fn {
+		delete(l.handlers, t)
+		return
+	}
+	if nil == l.handlers {
+		l.handlers = make(map[reflect.Type]Handler)
+	}
+	l.handlers[t] = fn
+}
+
+// KeyRenderer renders an element of the data map as JavaScript.
+// Like a Handler, the returned JavaScript is written to the
+// output as it is.
+type KeyRenderer func(interface{}) (template.JS, error)
+
+// SetKeyRenderer causes the element of the data map with the
+// specified key to be rendered by fn, rather than by the built-in
+// translation or any registered handler. This suits values whose
+// rendering depends on where they are, rather than on their type,
+// such as pre-rendered fragments. Only top-level keys of the data
+// map are affected, and the renderer applies to JSON as well, so
+// it should produce valid JSON if that's used. Setting a nil fn
+// removes the renderer for the key. If fn returns an error, the
+// element is replaced with null and the error is reported by
+// JSWithError and WriteJS.
+func (l *Map) SetKeyRenderer(key string, fn func(interface{}) (template.JS, error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if nil == fn {
+		delete(l.keyRenderers, key)
+		return
+	}
+	if nil == l.keyRenderers {
+		l.keyRenderers = make(map[string]KeyRenderer)
+	}
+	l.keyRenderers[key] = fn
+}
+
+// writeKeyRenderer writes the target as rendered by a key
+// renderer.
+func (e *encoder) writeKeyRenderer(fn KeyRenderer, target reflect.Value) {
+	var v interface{}
+	if target.IsValid() && target.CanInterface() {
+		v = target.Interface()
+	}
+	js, err := fn(v)
+	if nil != err {
+		e.failNull(fmt.Errorf(
+			"Failed to localize value at key, %v, err: %w",
+			e.keyPath(),
+			err,
+		))
+		return
+	}
+
+	e.write(string(js))
+}
@@ -0,0 +1,130 @@
+/**
+ * keycase.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// KeyCase describes how the Go names of struct fields are
+// converted to keys.
+type KeyCase int
+
+const (
+	// KeyCaseNone keeps the Go names as they are, e.g. "UserID",
+	// matching encoding/json. This is the default.
+	KeyCaseNone KeyCase = iota
+
+	// KeyCaseCamel converts the Go names to camelCase, e.g.
+	// "userID". Initialisms keep their case past the first word.
+	KeyCaseCamel
+
+	// KeyCaseSnake converts the Go names to snake_case, e.g.
+	// "user_id".
+	KeyCaseSnake
+
+	// KeyCaseKebab converts the Go names to kebab-case, e.g.
+	// "user-id".
+	KeyCaseKebab
+)
+
+var ErrInvalidKeyCase = fmt.Errorf("Invalid key case provided")
+
+// SetKeyCase assigns the case that the Go names of struct fields
+// are converted to, both for structs in the data and for the
+// fields passed to NewMapFromStruct. Fields named by their "json"
+// struct tag keep that name as it is. Keys of the data map and of
+// other maps aren't affected.
+func (l *Map) SetKeyCase(keyCase KeyCase) error {
+	switch keyCase {
+	case KeyCaseNone, KeyCaseCamel, KeyCaseSnake, KeyCaseKebab:
+	default:
+		return ErrInvalidKeyCase
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.keyCase = keyCase
+	return nil
+}
+
+// GetKeyCase retrieves the case that the Go names of struct
+// fields are converted to.
+func (l *Map) GetKeyCase() KeyCase {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.keyCase
+}
+
+// convert converts a Go name to the key case.
+func (c KeyCase) convert(name string) string {
+	if KeyCaseNone == c {
+		return name
+	}
+
+	words := splitWords(name)
+	switch c {
+	case KeyCaseCamel:
+		for i, word := range words {
+			if 0 == i {
+				words[i] = strings.ToLower(word)
+			} else {
+				r := []rune(word)
+				r[0] = unicode.ToUpper(r[0])
+				words[i] = string(r)
+			}
+		}
+		return strings.Join(words, "")
+	case KeyCaseSnake:
+		return strings.ToLower(strings.Join(words, "_"))
+	case KeyCaseKebab:
+		return strings.ToLower(strings.Join(words, "-"))
+	}
+	return name
+}
+
+// splitWords splits a Go name into its words, e.g. "UserID" into
+// "User" and "ID", or "HTTPServer" into "HTTP" and "Server".
+// Underscores separate words too, and digits stay with the word
+// before them.
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if '_' == runes[i] {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if start == i || !unicode.IsUpper(runes[i]) {
+			continue
+		}
+
+		// A word starts at an upper case letter that follows a
+		// lower case letter or digit, or that's followed by a
+		// lower case letter at the end of an initialism.
+		prev := runes[i-1]
+		next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -10,11 +10,17 @@ package localize
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"reflect"
 	"regexp"
+	"sort"
+	"strings"
+	"sync"
 )
 
 var _ Localizer = &Map{}
@@ -22,7 +28,7 @@ var _ Localizer = &Map{}
 // JSVariableRegex matches a valid JavaScript variable name.
 // Variable name documentation:
 // https://developer.mozilla.org/en-US/docs/Web/JavaScript/Guide/Grammar_and_types#Variables
-var JSVariableRegex = regexp.MustCompile(`^[a-zA-z_\$][a-zA-z_\$0-9]*$`)
+var JSVariableRegex = regexp.MustCompile(`^[a-zA-Z_\$][a-zA-Z_\$0-9]*$`)
 
 // JSReservedRegex matches reserved JavaScript keywords that
 // may not be used as variable names. Reserved keyword
@@ -34,7 +40,30 @@ var (
 	ErrReservedKeyword     = fmt.Errorf("Reserved variable name provided")
 	ErrInvalidVariableName = fmt.Errorf("Invalid variable name provided")
 	ErrInvalidKey          = fmt.Errorf("Invalid key name provided")
-	ErrInvalidData         = fmt.Errorf("Invalid data provided")
+	ErrNotStruct           = fmt.Errorf("Non-struct data provided")
+	ErrInvalidJSON         = fmt.Errorf("Invalid JSON data provided")
+
+	// ErrInvalidData is no longer returned, since any value may
+	// be added to the data map. It's kept only for compatibility.
+	ErrInvalidData = fmt.Errorf("Invalid data provided")
+
+	// ErrDuplicateKey indicates that AddUnique was provided with
+	// a key that's already in the data map, or that the keys of
+	// a map convert to the same object key.
+	ErrDuplicateKey = fmt.Errorf("Duplicate key provided")
+
+	// ErrUnsupportedKind indicates that some of the data, such
+	// as a channel or a function, has no JavaScript equivalent.
+	ErrUnsupportedKind = fmt.Errorf("Unsupported kind")
+
+	// ErrCyclicData indicates that some of the data refers back
+	// to itself, e.g. through a pointer cycle.
+	ErrCyclicData = fmt.Errorf("Cyclic data structure")
+
+	// ErrMaxDepth indicates that some of the data is nested
+	// deeper than the maximum depth.
+	ErrMaxDepth        = fmt.Errorf("Maximum depth exceeded")
+	ErrInvalidMaxDepth = fmt.Errorf("Invalid maximum depth provided")
 
 	// ErrNilMap most likely indicates that NewMap() was
 	// provided with a nil pointer.
@@ -61,55 +90,363 @@ type Localizer interface {
 
 // Map takes a set of data, translates it to JavaScript
 // primitives, and then formats it for insertion into a global
-// browser context.
+// browser context. A Map is safe for concurrent use, so that it
+// may be shared across HTTP handlers.
 type Map struct {
+	// mu guards all of the fields below.
+	mu sync.RWMutex
+
 	data       Data
 	globalName string
+
+	// strict causes the data to be localized as strict JSON.
+	strict bool
+
+	// strictKeys causes keys to be validated as identifiers.
+	strictKeys bool
+
+	// maxDepth limits the nesting of objects and arrays, unless
+	// it's zero.
+	maxDepth int
+
+	// timeFormat and timeLayout determine how time.Time values
+	// are localized.
+	timeFormat TimeFormat
+	timeLayout string
+
+	// durationFormat determines how time.Duration values are
+	// localized.
+	durationFormat DurationFormat
+
+	// bigFormat determines how big.Int and big.Float values are
+	// localized.
+	bigFormat BigFormat
+
+	// floatPolicy determines how NaN and ±Inf float values are
+	// localized.
+	floatPolicy FloatPolicy
+
+	// intPolicy determines how integers outside of JavaScript's
+	// safe range are localized.
+	intPolicy IntPolicy
+
+	// stringers causes values that implement fmt.Stringer to be
+	// localized as their String() label.
+	stringers bool
+
+	// runes causes rune values to be localized as characters.
+	runes bool
+
+	// keyCase determines how the Go names of struct fields are
+	// converted to keys.
+	keyCase KeyCase
+
+	// pretty causes the output to be indented with the prefix
+	// and indent.
+	pretty bool
+	prefix string
+	indent string
+
+	// minify causes insignificant whitespace to be left out.
+	minify bool
+
+	// newline determines the line breaks of the output.
+	newline Newline
+
+	// noEscapeHTML causes the HTML-significant characters of
+	// strings to be left unescaped.
+	noEscapeHTML bool
+
+	// declaration determines the keyword that declares the
+	// global variable, if any.
+	declaration Declaration
+
+	// globalObject holds the object that the global variable is
+	// assigned to as a property, if any.
+	globalObject string
+
+	// bracketed causes the global variable name to be assigned
+	// as a computed property of the global object, for names
+	// that aren't valid identifiers.
+	bracketed bool
+
+	// callback holds the function that the data is passed to, in
+	// place of the global variable assignment.
+	callback string
+
+	// freeze causes the data to be wrapped in Object.freeze.
+	freeze bool
+
+	// omitSemicolon causes the trailing semicolon to be left out
+	// of the output.
+	omitSemicolon bool
+
+	// scriptType determines the type attribute of rendered
+	// script elements.
+	scriptType ScriptType
+
+	// handlers holds the custom renderers of registered types.
+	handlers map[reflect.Type]Handler
+
+	// keyRenderers holds the custom renderers of elements of the
+	// data map, by key.
+	keyRenderers map[string]KeyRenderer
 }
 
-// NewMap generates a new localization map.
-func NewMap(name string, data Data) (*Map, error) {
+// NewMap generates a new localization map, configured by any
+// options that are provided. The options are applied before the
+// global name is validated, so that the name is checked against
+// the final declaration, e.g. an empty name is accepted with
+// DeclarationExportDefault.
+func NewMap(name string, data Data, opts ...Option) (*Map, error) {
 	if nil == data {
 		data = Data{}
 	}
 	l := &Map{
-		data: data,
+		data:       data,
+		globalName: name,
 	}
-	if err := l.SetGlobalName(name); nil != err {
+	for _, opt := range opts {
+		if err := opt(l); nil != err {
+			return nil, err
+		}
+	}
+	if !l.bracketed {
+		if err := validateGlobalName(l.globalName, l.declaration); nil != err {
+			return nil, err
+		}
+	}
+	if l.strictKeys {
+		if err := l.validateData(l.data); nil != err {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// NewMapFromStruct generates a new localization map from the
+// exported fields of a struct, or a pointer to one. The fields
+// become the elements of the data map, named by their "json"
+// struct tags in the same way that nested structs are localized,
+// and those with the "string" option hold their value as a string.
+// Any other kind of input returns an error wrapping ErrNotStruct.
+func NewMapFromStruct(name string, v interface{}, opts ...Option) (*Map, error) {
+	target := reflect.ValueOf(v)
+	for reflect.Ptr == target.Kind() && !target.IsNil() {
+		target = target.Elem()
+	}
+	if reflect.Struct != target.Kind() {
+		return nil, fmt.Errorf("%w: %T", ErrNotStruct, v)
+	}
+
+	// The options are applied first, since the key case
+	// determines the names of the fields.
+	l, err := NewMap(name, nil, opts...)
+	if nil != err {
+		return nil, err
+	}
+	fields := structFields(target, l.keyCase)
+	data := make(Data, len(fields))
+	for _, f := range fields {
+		data[f.name] = f.value.Interface()
+
+		// The elements aren't fields anymore, so those with the
+		// "string" option hold their quoted value instead.
+		if s, ok := quotedString(f.value, quote); ok && f.quoted {
+			data[f.name] = s
+		}
+	}
+	if err := l.SetData(data); nil != err {
 		return nil, err
 	}
 	return l, nil
 }
 
+// NewMapFromJSON generates a new localization map from a JSON
+// object. The global variable is an object, so any other JSON
+// value, such as an array or null, is rejected along with
+// malformed JSON. The returned errors wrap ErrInvalidJSON.
+//
+// JSON numbers are decoded as float64 values, which are the same
+// doubles that JavaScript's JSON.parse produces, so the localized
+// numbers hold the same values as in the browser. They're written
+// in the same shortest form as JSON.stringify, so whole numbers
+// have no fraction, e.g. 42 rather than 42.0, and very large or
+// small ones use an exponent, e.g. 1e+21.
+func NewMapFromJSON(name string, raw []byte, opts ...Option) (*Map, error) {
+	data, err := decodeObject(raw)
+	if nil != err {
+		return nil, err
+	}
+	return NewMap(name, data, opts...)
+}
+
+// UnmarshalJSON replaces the data map with a JSON object, which
+// allows the data to be loaded from a JSON config file with
+// json.Unmarshal, for example. The global name and the options
+// are left untouched, so a map that's unmarshaled into from its
+// zero value still needs a global name. As with NewMapFromJSON,
+// any JSON value other than an object is rejected, and the
+// returned errors wrap ErrInvalidJSON, except that null leaves
+// the map unchanged, following the encoding/json convention. The
+// elements are validated in the same way as by SetData.
+func (l *Map) UnmarshalJSON(raw []byte) error {
+	if "null" == string(bytes.TrimSpace(raw)) {
+		return nil
+	}
+	data, err := decodeObject(raw)
+	if nil != err {
+		return err
+	}
+	return l.SetData(data)
+}
+
+// decodeObject parses a JSON object into a data map.
+func decodeObject(raw []byte) (Data, error) {
+	var data Data
+	if err := json.Unmarshal(raw, &data); nil != err {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	if nil == data {
+		return nil, fmt.Errorf("%w: not an object", ErrInvalidJSON)
+	}
+	return data, nil
+}
+
 // Add inserts an element with the specified key to the data
-// map.
+// map. A nil element is localized as null.
 func (l *Map) Add(key string, data interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	if nil == l.data {
 		return ErrNilMap
 	}
-	if "" == key {
-		return ErrInvalidKey
-	}
-	if nil == data {
-		return ErrInvalidData
+	if err := l.validateElement(key); nil != err {
+		return err
 	}
 
 	l.data[key] = data
-	if val, ok := l.data[key]; !ok || nil == val {
+	if _, ok := l.data[key]; !ok {
 		return errors.New("Failed to add element")
 	}
 
 	return nil
 }
 
+// AddUnique inserts an element with the specified key to the
+// data map, like Add, unless the key is already in use. Rather
+// than silently overwriting the existing element, an error
+// wrapping ErrDuplicateKey is returned, naming the key.
+func (l *Map) AddUnique(key string, data interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if nil == l.data {
+		return ErrNilMap
+	}
+	if err := l.validateElement(key); nil != err {
+		return err
+	}
+	if _, ok := l.data[key]; ok {
+		return fmt.Errorf("%w: key %q", ErrDuplicateKey, key)
+	}
+
+	l.data[key] = data
+	return nil
+}
+
+// AddMany inserts every element of the provided data into the
+// data map. Each element is validated in the same way as Add,
+// in key order. Insertion is all-or-nothing: if any element is
+// invalid, an error naming its key is returned, and none of the
+// elements are inserted.
+func (l *Map) AddMany(data Data) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if nil == l.data {
+		return ErrNilMap
+	}
+	if err := l.validateData(data); nil != err {
+		return err
+	}
+
+	for key, val := range data {
+		l.data[key] = val
+	}
+
+	return nil
+}
+
+// SetData replaces the data map wholesale. As with NewMap, the
+// provided map is used directly rather than copied, and a nil map
+// is treated as an empty one. Each element is validated in the
+// same way as Add, in key order, and if any element is invalid,
+// an error naming its key is returned and the data map is left
+// unchanged.
+func (l *Map) SetData(data Data) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if nil == data {
+		data = Data{}
+	}
+	if err := l.validateData(data); nil != err {
+		return err
+	}
+
+	l.data = data
+	return nil
+}
+
+// validateData checks every element of the data, in key order,
+// so that the first invalid key is reported consistently. The
+// caller must hold the lock.
+func (l *Map) validateData(data Data) error {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := l.validateElement(key); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateElement checks that an element may be inserted into
+// the data map under the key. Under StrictKeys, the key must
+// also be a valid identifier that isn't a reserved keyword. The
+// returned errors wrap the ErrInvalidKey or ErrReservedKeyword
+// sentinels, and name the offending key. The caller must hold
+// the lock.
+func (l *Map) validateElement(key string) error {
+	if "" == key {
+		return fmt.Errorf("%w: key %q", ErrInvalidKey, key)
+	}
+	if l.strictKeys && !JSVariableRegex.MatchString(key) {
+		return fmt.Errorf("%w: key %q", ErrInvalidKey, key)
+	}
+	if l.strictKeys && JSReservedRegex.MatchString(key) {
+		return fmt.Errorf("%w: key %q", ErrReservedKeyword, key)
+	}
+	return nil
+}
+
 // Delete removes an element with the specified key from the
 // data map.
 func (l *Map) Delete(key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	if nil == l.data {
 		return ErrNilMap
 	}
 	if "" == key {
-		return ErrInvalidKey
+		return fmt.Errorf("%w: key %q", ErrInvalidKey, key)
 	}
 
 	delete(l.data, key)
@@ -123,15 +460,104 @@ func (l *Map) Delete(key string) error {
 	return nil
 }
 
-// GetData retrieves the localization map's data.
+// Reset removes every element from the data map, while keeping
+// the global name and the formatting options, so that the map
+// can be reused.
+func (l *Map) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.data = Data{}
+}
+
+// Has reports whether the data map contains an element with
+// the specified key.
+func (l *Map) Has(key string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, ok := l.data[key]
+	return ok
+}
+
+// Keys retrieves the keys of the data map, in sorted order.
+func (l *Map) Keys() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	keys := make([]string, 0, len(l.data))
+	for key := range l.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Len retrieves the number of elements in the data map.
+func (l *Map) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.data)
+}
+
+// GetData retrieves a shallow copy of the localization map's
+// data. Adding or deleting keys on the returned map doesn't
+// affect the localization map, however nested maps and slices
+// are still shared with it.
 func (l *Map) GetData() Data {
-	return l.data
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	data := make(Data, len(l.data))
+	for key, val := range l.data {
+		data[key] = val
+	}
+	return data
 }
 
 // SetGlobalName assigns the localization map's global
 // JavaScript variable name, which will receive the localized
-// data.
+// data. The name may also be a dotted path, such as
+// "App.config", to assign the data into an existing namespace
+// object. The name isn't validated while the declaration is
+// DeclarationExportDefault or DeclarationCommonJS, since no
+// variable is needed.
 func (l *Map) SetGlobalName(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := validateGlobalName(name, l.declaration); nil != err {
+		return err
+	}
+	l.globalName = name
+	l.bracketed = false
+	return nil
+}
+
+// validateGlobalName checks that the name can receive the data
+// with the declaration. Each segment of a dotted path must be a
+// valid variable name, and since only a bare variable can be
+// declared, a dotted path requires DeclarationNone.
+func validateGlobalName(name string, declaration Declaration) error {
+	if !declaration.named() {
+		return nil
+	}
+	segments := strings.Split(name, ".")
+	for _, segment := range segments {
+		if err := validateIdentifier(segment); nil != err {
+			return err
+		}
+	}
+	if 1 < len(segments) && DeclarationNone != declaration {
+		return ErrIncompatibleOptions
+	}
+	return nil
+}
+
+// validateIdentifier checks that the name is a valid JavaScript
+// variable name.
+func validateIdentifier(name string) error {
 	var buf bytes.Buffer
 	buf.WriteString(name)
 	bytes := buf.Bytes()
@@ -141,17 +567,90 @@ func (l *Map) SetGlobalName(name string) error {
 	if ok := JSReservedRegex.Match(bytes); ok {
 		return ErrReservedKeyword
 	}
-
-	l.globalName = name
 	return nil
 }
 
 // GetGlobalName retrieves the localization map's global
 // JavaScript variable name.
 func (l *Map) GetGlobalName() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
 	return l.globalName
 }
 
+// SetStrict toggles strict mode. In strict mode the localized
+// data is written as strict JSON, with commas only between
+// elements, so that the object can also be consumed by
+// JSON.parse() and other strict parsers. By default, every
+// element carries a trailing comma.
+func (l *Map) SetStrict(strict bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.strict = strict
+}
+
+// GetStrict reports whether the localization map is in strict
+// mode.
+func (l *Map) GetStrict() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.strict
+}
+
+// SetStrictKeys toggles the validation of keys as JavaScript
+// identifiers. Under StrictKeys, keys that couldn't be accessed
+// with dot notation, such as "foo bar" or "2foo", and reserved
+// keywords, such as "delete" or "class", are rejected by Add,
+// AddMany, and SetData. Elements that are already in the
+// data map aren't affected.
+func (l *Map) SetStrictKeys(strict bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.strictKeys = strict
+}
+
+// GetStrictKeys reports whether keys are validated as JavaScript
+// identifiers.
+func (l *Map) GetStrictKeys() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.strictKeys
+}
+
+// SetMaxDepth limits how deeply objects and arrays may be nested
+// in the localized data, which guards against accidentally deep
+// or adversarial data, such as JSON passed to NewMapFromJSON. The
+// data map itself is at depth one, so a maximum depth of one only
+// allows values without any nesting. Values nested any deeper are
+// replaced with null, and reported with an error wrapping
+// ErrMaxDepth. A maximum depth of zero, the default, means that
+// the nesting is unlimited.
+func (l *Map) SetMaxDepth(depth int) error {
+	if 0 > depth {
+		return ErrInvalidMaxDepth
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maxDepth = depth
+	return nil
+}
+
+// GetMaxDepth retrieves the maximum nesting depth of the
+// localized data, or zero if it's unlimited.
+func (l *Map) GetMaxDepth() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.maxDepth
+}
+
 // JS gets a valid block of template.JS data that represents
 // the fields of this Map's "data" field and all its
 // children. The returned template.JS block can be directly
@@ -159,16 +658,179 @@ func (l *Map) GetGlobalName() string {
 // "html/template" package) and output as valid JavaScript
 // code.
 func (l *Map) JS() template.JS {
-	// Generates a buffer that will have the JavaScript
-	// string-formatted bytes written to it. The head of the
-	// buffer is a global variable assignment.
-	buf := bytes.NewBuffer([]byte(fmt.Sprintf("%s = {\n", l.globalName)))
+	js, _ := l.JSWithError()
+	return js
+}
+
+// JSWithError behaves like JS, but also reports the first
+// value that couldn't be localized. Unsupported values, such as
+// channels and functions, are replaced with null in the returned
+// template.JS block, and reported with an error wrapping
+// ErrUnsupportedKind. So a non-nil error means that the output
+// is missing some of the data.
+func (l *Map) JSWithError() (template.JS, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	_, err := l.WriteJS(buf)
+
+	return template.JS(buf.String()), err
+}
+
+// WriteJS streams the same JavaScript that JS produces directly
+// to the writer, rather than building it in memory. It returns
+// the number of bytes written and the first error encountered,
+// either from the writer or from data that couldn't be
+// localized (see JSWithError).
+func (l *Map) WriteJS(w io.Writer) (int, error) {
+	return l.writeJS(context.Background(), w)
+}
+
+// AppendTo appends the same JavaScript that JS produces to the
+// buffer, so that several maps can be composed into one script
+// block without building each of them separately. When the
+// buffer already holds some script, a line break separates the
+// two. Errors are reported in the same way as by JSWithError.
+func (l *Map) AppendTo(buf *bytes.Buffer) error {
+	if 0 < buf.Len() {
+		buf.WriteString(l.GetNewline().String())
+	}
+	_, err := l.WriteJS(buf)
+	return err
+}
+
+// JSContext behaves like JSWithError, but gives up as soon as
+// the context is done, which suits large data maps being
+// localized for a request that may be abandoned. The context is
+// checked periodically while the data is walked, and once it's
+// done, the context's error is returned without any output.
+func (l *Map) JSContext(ctx context.Context) (template.JS, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	_, err := l.writeJS(ctx, buf)
+	if nil != ctx.Err() && errors.Is(err, ctx.Err()) {
+		return "", err
+	}
+
+	return template.JS(buf.String()), err
+}
+
+// writeJS writes the JavaScript to the writer, giving up once
+// the context is done.
+func (l *Map) writeJS(ctx context.Context, w io.Writer) (int, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	e := l.newEncoder(w)
+	if nil != ctx.Done() {
+		e.ctx = ctx
+	}
+
+	// The head of the output is a global variable assignment,
+	// or a callback, and the enclosing braces of the data map
+	// are written along with it.
+	if "" != l.callback {
+		e.write(l.callback + "(")
+	} else {
+		e.write(l.assignment(e.minify))
+	}
+	if l.freeze {
+		e.write("Object.freeze(")
+	}
+	e.reflect(reflect.ValueOf(l.data))
+	if l.freeze {
+		e.write(")")
+	}
+	if "" != l.callback {
+		e.write(")")
+	}
+	if !l.omitSemicolon {
+		e.write(";")
+	}
 
-	// Fills the buffer.
-	ReflectTarget(reflect.ValueOf(l.data), buf)
-	buf.Write([]byte("\n};"))
+	return e.n, e.error()
+}
 
-	return template.JS(buf.String())
+// JSON serializes the localization map's data as strict JSON,
+// without the global variable assignment. This allows the same
+// data to be served by an API endpoint, for example. Unlike
+// JSWithError, no output is returned when some of the data
+// couldn't be localized.
+func (l *Map) JSON() ([]byte, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	e := l.newEncoder(buf)
+	e.strict = true
+	e.reflect(reflect.ValueOf(l.data))
+	if err := e.error(); nil != err {
+		return nil, err
+	}
+
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// bufferPool holds the buffers that the output is built in, so
+// that they're reused across calls rather than churning the
+// garbage collector under high request rates.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// maxPooledBuffer is the capacity, in bytes, above which buffers
+// aren't returned to the pool, so that one unusually large map
+// doesn't pin its memory indefinitely.
+const maxPooledBuffer = 1 << 16
+
+// getBuffer retrieves an empty buffer from the pool. The output
+// must be copied out of the buffer before it's returned with
+// putBuffer.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns the buffer to the pool.
+func putBuffer(buf *bytes.Buffer) {
+	if maxPooledBuffer < buf.Cap() {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// newEncoder prepares an encoder that writes to the writer with
+// the localization map's formatting choices. The caller must
+// hold the lock.
+func (l *Map) newEncoder(w io.Writer) *encoder {
+	return &encoder{
+		w:          w,
+		strict:     l.strict,
+		timeFormat: l.timeFormat,
+		timeLayout: l.timeLayout,
+
+		durationFormat: l.durationFormat,
+		bigFormat:      l.bigFormat,
+		floatPolicy:    l.floatPolicy,
+		intPolicy:      l.intPolicy,
+		stringers:      l.stringers,
+		maxDepth:       l.maxDepth,
+		runes:          l.runes,
+		keyCase:        l.keyCase,
+
+		pretty: l.pretty,
+		prefix: l.prefix,
+		indent: l.indent,
+		minify: l.minify,
+
+		newline:      l.newline,
+		noEscapeHTML: l.noEscapeHTML,
+
+		handlers:     l.handlers,
+		keyRenderers: l.keyRenderers,
+	}
 }
 
 // ReflectTarget takes a reflect.Value object and recursively
@@ -180,66 +842,21 @@ func (l *Map) JS() template.JS {
 // for translating data to a JavaScript array. Curly-brackets
 // ("{}") are used for translating data to a JavaScript object.
 // Non-enclosing types simply output according to their
-// JavaScript equivalent.
+// JavaScript equivalent, as written by the encoding/json
+// package. This means that strings are escaped (including
+// control characters, which become \uXXXX escapes, and the
+// HTML-significant characters, so that a value such as
+// "</script>" can't close the surrounding script element) and
+// floats are rendered with the fewest digits needed to
+// represent the value at its own precision, so a float32
+// renders as its 32-bit value (e.g. 1.5 or 0.1). Complex
+// numbers have no JavaScript equivalent, and are written as
+// two-element [real, imag] arrays.
 //
 // The complete contents of the top-most target is written
-// piece-by-piece to the buffer provided.
-func ReflectTarget(target reflect.Value, buf *bytes.Buffer) {
-	targetType := target.Type().Kind().String()
-	switch targetType {
-	case "interface":
-		f := target.Elem()
-
-		ReflectTarget(f, buf)
-	case "struct":
-		numFields := target.NumField()
-		for i := 0; i < numFields; i++ {
-			f := target.Field(i)
-
-			buf.Write([]byte(fmt.Sprintf("\"%s\": {\n", target.Type().Field(i).Name)))
-			ReflectTarget(f, buf)
-			buf.Write([]byte(fmt.Sprint("},\n")))
-		}
-	case "map":
-		keys := target.MapKeys()
-		for _, keyValue := range keys {
-			f := target.MapIndex(keyValue)
-			fType := f.Type().Kind().String()
-
-			if "map" == fType || "interface" == fType {
-				cOpen := "{"
-				cClose := "}"
-
-				if "interface" == fType && "map" != f.Elem().Type().Kind().String() {
-					cOpen = "["
-					cClose = "]"
-				}
-
-				buf.Write([]byte(fmt.Sprintf("\"%s\": %s\n", keyValue, cOpen)))
-				ReflectTarget(f, buf)
-				buf.Write([]byte(fmt.Sprintf("\n%s,\n", cClose)))
-			} else {
-				buf.Write([]byte(fmt.Sprintf("\"%s\":", keyValue)))
-				ReflectTarget(f, buf)
-				buf.Write([]byte(fmt.Sprint("\n")))
-			}
-		}
-	case "slice":
-		sliceLen := target.Len()
-		buf.Write([]byte(fmt.Sprint("[")))
-		for i := 0; i < sliceLen; i++ {
-			f := target.Index(i)
-
-			ReflectTarget(f, buf)
-		}
-		buf.Write([]byte(fmt.Sprint("],\n")))
-	case "int":
-		buf.Write([]byte(fmt.Sprintf("%v,", target.Int())))
-	case "string":
-		buf.Write([]byte(fmt.Sprintf("\"%v\",", target.String())))
-	case "bool":
-		buf.Write([]byte(fmt.Sprintf("%v,", target.Bool())))
-	case "float64":
-		buf.Write([]byte(fmt.Sprintf("%v,", target.Float())))
-	}
+// piece-by-piece to the writer provided, such as a
+// *bytes.Buffer or an http.ResponseWriter. Errors are not
+// reported, see WriteJS for a variant that does.
+func ReflectTarget(target reflect.Value, w io.Writer) {
+	(&encoder{w: w}).reflect(target)
 }
@@ -0,0 +1,344 @@
+/**
+ * options.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"reflect"
+)
+
+// Option configures a localization map as it's generated by
+// NewMap. Options are applied in order, after the data, so an
+// option that depends on another, such as WithGlobalObject and
+// WithDeclaration, is subject to the same rules as the
+// corresponding setters. The global name is validated once all of
+// the options have been applied.
+type Option func(l *Map) error
+
+// WithGlobalName replaces the global name given to the
+// constructor, as in SetGlobalName. This suits constructors whose
+// name argument is easily left empty, such as NewMapFromStruct.
+// Since SetDeclaration checks the current name, WithGlobalName
+// should come before WithDeclaration.
+func WithGlobalName(name string) Option {
+	return func(l *Map) error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.globalName = name
+		l.bracketed = false
+		return nil
+	}
+}
+
+// WithStrict sets the strict mode, as in SetStrict.
+func WithStrict(strict bool) Option {
+	return func(l *Map) error {
+		l.SetStrict(strict)
+		return nil
+	}
+}
+
+// WithStrictKeys toggles the validation of keys, as in
+// SetStrictKeys. Unlike SetStrictKeys, the initial data is
+// validated too.
+func WithStrictKeys(strict bool) Option {
+	return func(l *Map) error {
+		l.SetStrictKeys(strict)
+		return nil
+	}
+}
+
+// WithMaxDepth limits the nesting depth, as in SetMaxDepth.
+func WithMaxDepth(depth int) Option {
+	return func(l *Map) error {
+		return l.SetMaxDepth(depth)
+	}
+}
+
+// WithTimeFormat sets the time format, as in SetTimeFormat.
+func WithTimeFormat(format TimeFormat, layout string) Option {
+	return func(l *Map) error {
+		return l.SetTimeFormat(format, layout)
+	}
+}
+
+// WithDurationFormat sets the duration format, as in
+// SetDurationFormat.
+func WithDurationFormat(format DurationFormat) Option {
+	return func(l *Map) error {
+		return l.SetDurationFormat(format)
+	}
+}
+
+// WithBigFormat sets the big number format, as in SetBigFormat.
+func WithBigFormat(format BigFormat) Option {
+	return func(l *Map) error {
+		return l.SetBigFormat(format)
+	}
+}
+
+// WithFloatPolicy sets the float policy, as in SetFloatPolicy.
+func WithFloatPolicy(policy FloatPolicy) Option {
+	return func(l *Map) error {
+		return l.SetFloatPolicy(policy)
+	}
+}
+
+// WithIntPolicy sets the integer policy, as in SetIntPolicy.
+func WithIntPolicy(policy IntPolicy) Option {
+	return func(l *Map) error {
+		return l.SetIntPolicy(policy)
+	}
+}
+
+// WithStringers toggles the Stringer labels, as in SetStringers.
+func WithStringers(enabled bool) Option {
+	return func(l *Map) error {
+		l.SetStringers(enabled)
+		return nil
+	}
+}
+
+// WithRunes toggles the rune characters, as in SetRunes.
+func WithRunes(enabled bool) Option {
+	return func(l *Map) error {
+		l.SetRunes(enabled)
+		return nil
+	}
+}
+
+// WithKeyCase sets the key case, as in SetKeyCase.
+func WithKeyCase(keyCase KeyCase) Option {
+	return func(l *Map) error {
+		return l.SetKeyCase(keyCase)
+	}
+}
+
+// WithIndent indents the output, as in SetIndent.
+func WithIndent(prefix, indent string) Option {
+	return func(l *Map) error {
+		l.SetIndent(prefix, indent)
+		return nil
+	}
+}
+
+// WithMinify toggles the minified output, as in SetMinify.
+func WithMinify(minify bool) Option {
+	return func(l *Map) error {
+		l.SetMinify(minify)
+		return nil
+	}
+}
+
+// WithNewline sets the line breaks, as in SetNewline.
+func WithNewline(newline Newline) Option {
+	return func(l *Map) error {
+		return l.SetNewline(newline)
+	}
+}
+
+// WithEscapeHTML toggles the escaping of HTML-significant
+// characters, as in SetEscapeHTML.
+func WithEscapeHTML(escape bool) Option {
+	return func(l *Map) error {
+		l.SetEscapeHTML(escape)
+		return nil
+	}
+}
+
+// WithDeclaration sets the declaration keyword, as in
+// SetDeclaration.
+func WithDeclaration(declaration Declaration) Option {
+	return func(l *Map) error {
+		return l.SetDeclaration(declaration)
+	}
+}
+
+// WithGlobalObject sets the global object, as in
+// SetGlobalObject.
+func WithGlobalObject(object string) Option {
+	return func(l *Map) error {
+		return l.SetGlobalObject(object)
+	}
+}
+
+// WithCallback sets the callback, as in SetCallback.
+func WithCallback(callback string) Option {
+	return func(l *Map) error {
+		return l.SetCallback(callback)
+	}
+}
+
+// WithFreeze toggles the Object.freeze wrapper, as in SetFreeze.
+func WithFreeze(freeze bool) Option {
+	return func(l *Map) error {
+		l.SetFreeze(freeze)
+		return nil
+	}
+}
+
+// WithOmitSemicolon toggles the trailing semicolon, as in
+// SetOmitSemicolon.
+func WithOmitSemicolon(omit bool) Option {
+	return func(l *Map) error {
+		l.SetOmitSemicolon(omit)
+		return nil
+	}
+}
+
+// WithScriptType sets the script type, as in SetScriptType.
+func WithScriptType(scriptType ScriptType) Option {
+	return func(l *Map) error {
+		return l.SetScriptType(scriptType)
+	}
+}
+
+// WithHandler registers a handler, as in RegisterHandler.
+func WithHandler(t reflect.Type, fn func(reflect.Value) (string, error)) Option {
+	return func(l *Map) error {
+		l.RegisterHandler(t, fn)
+		return nil
+	}
+}
+
+// Options holds the configuration of a localization map as plain
+// data, as an alternative to the functional options. The zero
+// value of each field is the default.
+type Options struct {
+	Strict     bool
+	StrictKeys bool
+	MaxDepth   int
+
+	TimeFormat     TimeFormat
+	TimeLayout     string
+	DurationFormat DurationFormat
+	BigFormat      BigFormat
+	FloatPolicy    FloatPolicy
+	IntPolicy      IntPolicy
+	Stringers      bool
+	Runes          bool
+	KeyCase        KeyCase
+
+	// The output is indented when either Prefix or Indent is
+	// non-empty.
+	Prefix  string
+	Indent  string
+	Minify  bool
+	Newline Newline
+
+	// NoEscapeHTML disables the escaping of HTML-significant
+	// characters, see SetEscapeHTML.
+	NoEscapeHTML bool
+
+	Declaration  Declaration
+	GlobalObject string
+
+	// Bracketed assigns the global name as a computed property of
+	// the GlobalObject, see SetGlobalNameBracketed.
+	Bracketed bool
+
+	Callback      string
+	Freeze        bool
+	OmitSemicolon bool
+	ScriptType    ScriptType
+}
+
+// options converts the configuration to the equivalent
+// functional options.
+func (o Options) options() []Option {
+	var opts []Option
+	if o.Bracketed {
+		// The bracketed name comes first, so that the declaration
+		// doesn't validate it as a variable name.
+		opts = append(opts, withGlobalNameBracketed(o.GlobalObject))
+	}
+	opts = append(opts,
+		WithStrict(o.Strict),
+		WithStrictKeys(o.StrictKeys),
+		WithMaxDepth(o.MaxDepth),
+		WithTimeFormat(o.TimeFormat, o.TimeLayout),
+		WithDurationFormat(o.DurationFormat),
+		WithBigFormat(o.BigFormat),
+		WithFloatPolicy(o.FloatPolicy),
+		WithIntPolicy(o.IntPolicy),
+		WithStringers(o.Stringers),
+		WithRunes(o.Runes),
+		WithKeyCase(o.KeyCase),
+		WithMinify(o.Minify),
+		WithNewline(o.Newline),
+		WithEscapeHTML(!o.NoEscapeHTML),
+		WithDeclaration(o.Declaration),
+		WithGlobalObject(o.GlobalObject),
+		WithCallback(o.Callback),
+		WithFreeze(o.Freeze),
+		WithOmitSemicolon(o.OmitSemicolon),
+		WithScriptType(o.ScriptType),
+	)
+	if "" != o.Prefix || "" != o.Indent {
+		opts = append(opts, WithIndent(o.Prefix, o.Indent))
+	}
+	return opts
+}
+
+// withGlobalNameBracketed assigns the global name given to the
+// constructor as a computed property of the object, as in
+// SetGlobalNameBracketed.
+func withGlobalNameBracketed(object string) Option {
+	return func(l *Map) error {
+		l.mu.RLock()
+		key := l.globalName
+		l.mu.RUnlock()
+
+		return l.SetGlobalNameBracketed(object, key)
+	}
+}
+
+// NewMapWithOptions generates a new localization map, configured
+// by the options. Invalid or incompatible options are reported in
+// the same way as by the corresponding setters.
+func NewMapWithOptions(name string, data Data, opts Options) (*Map, error) {
+	return NewMap(name, data, opts.options()...)
+}
+
+// GetOptions retrieves the configuration of the localization map.
+// Registered handlers and key renderers aren't included.
+func (l *Map) GetOptions() Options {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	o := Options{
+		Strict:         l.strict,
+		StrictKeys:     l.strictKeys,
+		MaxDepth:       l.maxDepth,
+		TimeFormat:     l.timeFormat,
+		TimeLayout:     l.timeLayout,
+		DurationFormat: l.durationFormat,
+		BigFormat:      l.bigFormat,
+		FloatPolicy:    l.floatPolicy,
+		IntPolicy:      l.intPolicy,
+		Stringers:      l.stringers,
+		Runes:          l.runes,
+		KeyCase:        l.keyCase,
+		Minify:         l.minify,
+		Newline:        l.newline,
+		NoEscapeHTML:   l.noEscapeHTML,
+		Declaration:    l.declaration,
+		GlobalObject:   l.globalObject,
+		Bracketed:      l.bracketed,
+		Callback:       l.callback,
+		Freeze:         l.freeze,
+		OmitSemicolon:  l.omitSemicolon,
+		ScriptType:     l.scriptType,
+	}
+	if l.pretty {
+		o.Prefix = l.prefix
+		o.Indent = l.indent
+	}
+	return o
+}
@@ -0,0 +1,131 @@
+/**
+ * path.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrPathConflict indicates that a dotted path runs through
+	// an element that isn't a Data map.
+	ErrPathConflict = fmt.Errorf("Path conflicts with existing data")
+
+	// ErrPathNotFound indicates that a dotted path doesn't lead
+	// to an element.
+	ErrPathNotFound = fmt.Errorf("Path not found")
+)
+
+// SetPath inserts an element at the dotted path, e.g.
+// "ui.theme.color", creating the intermediate Data maps as
+// needed. Each key of the path is validated in the same way as
+// Add. If an intermediate key already holds something other than
+// a Data map, an error wrapping ErrPathConflict is returned,
+// naming the conflicting part of the path, and the data map is
+// left unchanged.
+func (l *Map) SetPath(path string, data interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if nil == l.data {
+		return ErrNilMap
+	}
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if err := l.validateElement(key); nil != err {
+			return err
+		}
+	}
+
+	// Conflicts are found before anything is created, so that a
+	// failed call doesn't leave behind empty maps.
+	parent := l.data
+	depth := 0
+	for ; depth < len(keys)-1; depth++ {
+		element, ok := parent[keys[depth]]
+		if !ok {
+			break
+		}
+		child, ok := element.(Data)
+		if !ok {
+			return fmt.Errorf(
+				"%w: key %q",
+				ErrPathConflict,
+				strings.Join(keys[:depth+1], "."),
+			)
+		}
+		parent = child
+	}
+	for ; depth < len(keys)-1; depth++ {
+		child := Data{}
+		parent[keys[depth]] = child
+		parent = child
+	}
+
+	parent[keys[len(keys)-1]] = data
+	return nil
+}
+
+// GetPath retrieves the element at the dotted path, e.g.
+// "ui.theme.color", and reports whether it exists. A path that
+// runs through something other than a Data map doesn't exist.
+func (l *Map) GetPath(path string) (interface{}, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	keys := strings.Split(path, ".")
+	parent, ok := l.parent(keys)
+	if !ok {
+		return nil, false
+	}
+	data, ok := parent[keys[len(keys)-1]]
+	return data, ok
+}
+
+// DeletePath removes the element at the dotted path, e.g.
+// "ui.theme.color". The Data maps along the path are kept, even
+// when they're left empty, so that the structure seen by
+// front-end scripts doesn't change, e.g. `ui.theme` is still an
+// object. If the path doesn't lead to an element, an error
+// wrapping ErrPathNotFound is returned, naming the path.
+func (l *Map) DeletePath(path string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if nil == l.data {
+		return ErrNilMap
+	}
+	keys := strings.Split(path, ".")
+	parent, ok := l.parent(keys)
+	if ok {
+		_, ok = parent[keys[len(keys)-1]]
+	}
+	if !ok {
+		return fmt.Errorf("%w: key %q", ErrPathNotFound, path)
+	}
+
+	delete(parent, keys[len(keys)-1])
+	return nil
+}
+
+// parent retrieves the Data map that holds the last key of the
+// path, and reports whether each of the keys before it leads to
+// a Data map. The caller must hold the lock.
+func (l *Map) parent(keys []string) (Data, bool) {
+	parent := l.data
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := parent[key].(Data)
+		if !ok {
+			return nil, false
+		}
+		parent = child
+	}
+	return parent, nil != parent
+}
@@ -0,0 +1,122 @@
+/**
+ * script.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+// ScriptType describes the type attribute of the script elements
+// rendered by Script and ScriptTag.
+type ScriptType int
+
+const (
+	// ScriptTypeJavaScript renders classic scripts, with
+	// type="text/javascript". This is the default.
+	ScriptTypeJavaScript ScriptType = iota
+
+	// ScriptTypeModule renders ES module scripts, with
+	// type="module", which suits the export declarations.
+	ScriptTypeModule
+)
+
+var ErrInvalidScriptType = fmt.Errorf("Invalid script type provided")
+
+// jsonEscaper escapes the HTML-significant characters of JSON
+// text. They may only appear inside of JSON strings, where the
+// escapes have the same meaning.
+var jsonEscaper = strings.NewReplacer(
+	"<", `\u003c`,
+	">", `\u003e`,
+	"&", `\u0026`,
+)
+
+// attribute retrieves the value of the type attribute.
+func (t ScriptType) attribute() string {
+	if ScriptTypeModule == t {
+		return "module"
+	}
+	return "text/javascript"
+}
+
+// SetScriptType assigns the type attribute of the script
+// elements rendered by Script and ScriptTag.
+func (l *Map) SetScriptType(scriptType ScriptType) error {
+	switch scriptType {
+	case ScriptTypeJavaScript, ScriptTypeModule:
+	default:
+		return ErrInvalidScriptType
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.scriptType = scriptType
+	return nil
+}
+
+// GetScriptType retrieves the type attribute of the script
+// elements rendered by Script and ScriptTag.
+func (l *Map) GetScriptType() ScriptType {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.scriptType
+}
+
+// Script wraps the localized data in a complete script element,
+// e.g. `<script type="text/javascript">_localData = {...};</script>`,
+// so that templates can render it with {{.LocalizedData.Script}}.
+// The localized strings can't close the element, as their
+// HTML-significant characters are escaped.
+func (l *Map) Script() template.HTML {
+	return l.script("")
+}
+
+// ScriptTag behaves like Script, but the script element also
+// carries the nonce, e.g.
+// `<script type="text/javascript" nonce="...">`, so that it may
+// run under a Content-Security-Policy that forbids inline scripts
+// without a nonce. The nonce is escaped as an attribute value.
+func (l *Map) ScriptTag(nonce string) template.HTML {
+	return l.script(nonce)
+}
+
+// JSONScript embeds the localized data as a JSON data block,
+// e.g. `<script type="application/json" id="data">{...}</script>`,
+// which can be read with
+// `JSON.parse(document.getElementById("data").textContent)`. As
+// data blocks aren't executed, they're allowed by a strict
+// Content-Security-Policy. The id is escaped as an attribute
+// value, and the HTML-significant characters of the JSON are
+// escaped, so that it can't close the element. Like JSON, the
+// block is left empty when some of the data couldn't be
+// localized.
+func (l *Map) JSONScript(id string) template.HTML {
+	data, _ := l.JSON()
+	return template.HTML(`<script type="application/json" id="` + html.EscapeString(id) + `">` +
+		jsonEscaper.Replace(string(data)) + "</script>")
+}
+
+// script renders the script element, with the nonce attribute
+// when one is provided.
+func (l *Map) script(nonce string) template.HTML {
+	js := l.JS()
+
+	l.mu.RLock()
+	open := `<script type="` + l.scriptType.attribute() + `"`
+	l.mu.RUnlock()
+	if "" != nonce {
+		open += ` nonce="` + html.EscapeString(nonce) + `"`
+	}
+	return template.HTML(open + ">" + string(js) + "</script>")
+}
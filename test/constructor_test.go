@@ -0,0 +1,364 @@
+/**
+ * constructor_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/foresthoffman/localize"
+)
+
+// config is a tagged struct that's localized as a whole.
+type config struct {
+	Motd    string            `json:"motd"`
+	Nonce   map[string]string `json:"nonce"`
+	Debug   bool              `json:"debug,omitempty"`
+	Secret  string            `json:"-"`
+	Version int
+	private string
+}
+
+// TestNewMapFromStruct ensures that the exported fields of a
+// struct become the elements of the data map.
+func TestNewMapFromStruct(t *testing.T) {
+	input := config{
+		Motd:    "Hello world!",
+		Nonce:   map[string]string{"login": "LaKJIIjIOUhjbKHdBJHGkhg"},
+		Secret:  "hunter2",
+		Version: 2,
+		private: "hidden",
+	}
+	expected := template.JS(`structCase = {
+"Version":2,
+"motd":"Hello world!",
+"nonce":{
+"login":"LaKJIIjIOUhjbKHdBJHGkhg",
+},
+};`)
+
+	structCases := map[string]interface{}{
+		"value":   input,
+		"pointer": &input,
+	}
+	for name, v := range structCases {
+		m, err := localize.NewMapFromStruct("structCase", v)
+		if nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Failed to create new map,\nerr: %v\n", err)
+			})
+			continue
+		}
+		if output := m.JS(); expected != output {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+			})
+		}
+	}
+
+	// Fields with the "string" option stay quoted at the top level.
+	quoted := struct {
+		ID   int64 `json:"id,string"`
+		Name int64 `json:"name"`
+	}{ID: 9007199254740993, Name: 2}
+	m, err := localize.NewMapFromStruct("quotedCase", quoted)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	expected = template.JS("quotedCase = {\n\"id\":\"9007199254740993\",\n\"name\":2,\n};")
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// TestNewMapFromNonStruct ensures that anything other than a
+// struct is rejected.
+func TestNewMapFromNonStruct(t *testing.T) {
+	var nilConfig *config
+	invalidCases := map[string]interface{}{
+		"nil":        nil,
+		"nilPointer": nilConfig,
+		"int":        1954,
+		"map":        localize.Data{"motd": "Hello world!"},
+	}
+	for name, v := range invalidCases {
+		if _, err := localize.NewMapFromStruct("structCase", v); !errors.Is(err, localize.ErrNotStruct) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrNotStruct, err)
+			})
+		}
+	}
+
+	// The global name is validated as usual.
+	if _, err := localize.NewMapFromStruct("5tructCase", config{}); !errors.Is(err, localize.ErrInvalidVariableName) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidVariableName, err)
+	}
+}
+
+// TestNewMapFromJSON ensures that JSON objects become the data
+// map, and that anything else is rejected.
+func TestNewMapFromJSON(t *testing.T) {
+	m, err := localize.NewMapFromJSON("jsonCase", []byte(`{"motd":"Hello world!","ids":[1,2],"nonce":{"login":"abc"}}`))
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	expected := template.JS(`jsonCase = {
+"ids":[1,2,],
+"motd":"Hello world!",
+"nonce":{
+"login":"abc",
+},
+};`)
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	invalidCases := map[string]string{
+		"array":     `[1,2,3]`,
+		"null":      `null`,
+		"malformed": `{"motd":`,
+		"empty":     ``,
+	}
+	for name, raw := range invalidCases {
+		if _, err := localize.NewMapFromJSON("jsonCase", []byte(raw)); !errors.Is(err, localize.ErrInvalidJSON) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidJSON, err)
+			})
+		}
+	}
+
+	// The underlying decoding error is kept.
+	var syntaxErr *json.SyntaxError
+	if _, err := localize.NewMapFromJSON("jsonCase", []byte(`{"motd":}`)); !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected err: %T,\ngot: %v\n", syntaxErr, err)
+	}
+}
+
+// TestUnmarshalJSON ensures that JSON objects replace the data of
+// an existing map, keeping its global name, and that anything
+// else is rejected.
+func TestUnmarshalJSON(t *testing.T) {
+	m, err := localize.NewMap("jsonCase", localize.Data{"stale": true})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := json.Unmarshal([]byte(`{"motd":"Hello world!","ids":[1,2]}`), m); nil != err {
+		t.Fatalf("Failed to unmarshal JSON,\nerr: %v\n", err)
+	}
+	expected := template.JS(`jsonCase = {
+"ids":[1,2,],
+"motd":"Hello world!",
+};`)
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	invalidCases := map[string]string{
+		"array":     `[1,2,3]`,
+		"string":    `"motd"`,
+		"malformed": `{"motd":`,
+	}
+	for name, raw := range invalidCases {
+		if err := m.UnmarshalJSON([]byte(raw)); !errors.Is(err, localize.ErrInvalidJSON) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidJSON, err)
+			})
+		}
+	}
+
+	// Null is ignored, as by encoding/json.
+	if err := m.UnmarshalJSON([]byte(`null`)); nil != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+	}
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// TestNewMapFromJSONNumbers ensures that numbers decoded from
+// JSON are localized as JSON.parse would produce them.
+func TestNewMapFromJSONNumbers(t *testing.T) {
+	numberCases := map[string]struct {
+		Input    string
+		Expected string
+	}{
+		"integer":    {`42`, `42`},
+		"negative":   {`-7`, `-7`},
+		"wholeFloat": {`42.0`, `42`},
+		"fraction":   {`3.14`, `3.14`},
+		"exponent":   {`2.5e3`, `2500`},
+		"large":      {`1e21`, `1e+21`},
+		"small":      {`0.0000001`, `1e-7`},
+		"unsafe":     {`9007199254740993`, `9007199254740992`},
+	}
+	for name, tCase := range numberCases {
+		m, err := localize.NewMapFromJSON("jsonCase", []byte(`{"n":`+tCase.Input+`}`))
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+
+		expected := "\"n\":" + tCase.Expected + ","
+		if output := string(m.JS()); !strings.Contains(output, expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to contain: %q,\ngot: %q\n", expected, output)
+			})
+		}
+	}
+}
+
+// TestNewMapOptions ensures that options passed to NewMap are
+// applied in order, and that invalid options are reported.
+func TestNewMapOptions(t *testing.T) {
+	m, err := localize.NewMap(
+		"optionCase",
+		localize.Data{"count": 2},
+		localize.WithDeclaration(localize.DeclarationConst),
+		localize.WithIndent("", "  "),
+	)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	expected := template.JS("const optionCase = {\n  \"count\": 2,\n};")
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	// WithGlobalName replaces the name given to the constructor.
+	m, err = localize.NewMapFromStruct(
+		"",
+		struct {
+			Count int `json:"count"`
+		}{2},
+		localize.WithGlobalName("structOptionCase"),
+		localize.WithDeclaration(localize.DeclarationConst),
+	)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	expected = template.JS("const structOptionCase = {\n\"count\":2,\n};")
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	errorCases := map[string]struct {
+		Data     localize.Data
+		Opts     []localize.Option
+		Expected error
+	}{
+		"depthCase": {
+			Opts:     []localize.Option{localize.WithMaxDepth(-1)},
+			Expected: localize.ErrInvalidMaxDepth,
+		},
+		"conflictCase": {
+			Opts: []localize.Option{
+				localize.WithGlobalObject("window"),
+				localize.WithDeclaration(localize.DeclarationLet),
+			},
+			Expected: localize.ErrIncompatibleOptions,
+		},
+		"nameCase": {
+			Opts:     []localize.Option{localize.WithGlobalName("2x")},
+			Expected: localize.ErrInvalidVariableName,
+		},
+		"keysCase": {
+			Data:     localize.Data{"foo bar": 1},
+			Opts:     []localize.Option{localize.WithStrictKeys(true)},
+			Expected: localize.ErrInvalidKey,
+		},
+	}
+	for name, tCase := range errorCases {
+		_, err := localize.NewMap(name, tCase.Data, tCase.Opts...)
+		if !errors.Is(err, tCase.Expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", tCase.Expected, err)
+			})
+		}
+	}
+}
+
+// TestNewMapExports ensures that the global name is validated
+// against the declaration given by the options, so that the
+// export declarations don't need one.
+func TestNewMapExports(t *testing.T) {
+	exportCases := map[localize.Declaration]string{
+		localize.DeclarationExportDefault: "export default {\n\"count\":2,\n};",
+		localize.DeclarationCommonJS:      "module.exports = {\n\"count\":2,\n};",
+	}
+	for declaration, expected := range exportCases {
+		m, err := localize.NewMap("", localize.Data{"count": 2}, localize.WithDeclaration(declaration))
+		if nil != err {
+			t.Errorf("Failed to create new map,\nerr: %v\n", err)
+			continue
+		}
+		if output := string(m.JS()); expected != output {
+			t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+		}
+
+		m, err = localize.NewMapWithOptions("", localize.Data{"count": 2}, localize.Options{Declaration: declaration})
+		if nil != err {
+			t.Errorf("Failed to create new map with options,\nerr: %v\n", err)
+			continue
+		}
+		if output := string(m.JS()); expected != output {
+			t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+		}
+	}
+
+	// Declarations that need a name still validate it.
+	if _, err := localize.NewMap("", nil, localize.WithDeclaration(localize.DeclarationConst)); localize.ErrInvalidVariableName != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidVariableName, err)
+	}
+	if _, err := localize.NewMap("2x", nil); localize.ErrInvalidVariableName != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidVariableName, err)
+	}
+}
+
+// TestNewMapWithOptions ensures that a fully populated Options
+// value configures the map, and reads back the same.
+func TestNewMapWithOptions(t *testing.T) {
+	opts := localize.Options{
+		Strict:         true,
+		StrictKeys:     true,
+		MaxDepth:       4,
+		TimeFormat:     localize.TimeFormatLayout,
+		TimeLayout:     "2006-01-02",
+		DurationFormat: localize.DurationFormatString,
+		BigFormat:      localize.BigFormatString,
+		FloatPolicy:    localize.FloatPolicyNull,
+		IntPolicy:      localize.IntPolicyString,
+		Stringers:      true,
+		Runes:          true,
+		Prefix:         "",
+		Indent:         "\t",
+		Minify:         false,
+		Declaration:    localize.DeclarationLet,
+		ScriptType:     localize.ScriptTypeModule,
+	}
+	m, err := localize.NewMapWithOptions("optionsCase", localize.Data{"id": uint64(1 << 60)}, opts)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if got := m.GetOptions(); opts != got {
+		t.Errorf("Expected: %+v,\ngot: %+v\n", opts, got)
+	}
+
+	expected := template.JS("let optionsCase = {\n\t\"id\": \"1152921504606846976\"\n};")
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	// Options are validated like the setters.
+	opts.IntPolicy = localize.IntPolicyBigInt
+	if _, err := localize.NewMapWithOptions("optionsCase", nil, opts); !errors.Is(err, localize.ErrIncompatibleOptions) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrIncompatibleOptions, err)
+	}
+}
@@ -0,0 +1,380 @@
+/**
+ * declaration_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/foresthoffman/localize"
+)
+
+// TestDeclaration ensures that the global variable is declared
+// with the chosen keyword.
+func TestDeclaration(t *testing.T) {
+	declarationCases := map[string]struct {
+		Declaration localize.Declaration
+		Expected    string
+	}{
+		"none":  {localize.DeclarationNone, "_localData = {"},
+		"var":   {localize.DeclarationVar, "var _localData = {"},
+		"let":   {localize.DeclarationLet, "let _localData = {"},
+		"const": {localize.DeclarationConst, "const _localData = {"},
+	}
+	for name, tCase := range declarationCases {
+		m, err := localize.NewMap("_localData", localize.Data{
+			"int": 1954,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetDeclaration(tCase.Declaration); nil != err {
+			t.Fatalf("Failed to set declaration,\nerr: %v\n", err)
+		}
+
+		if output := string(m.JS()); !strings.HasPrefix(output, tCase.Expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to start with: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+
+	m, err := localize.NewMap("_localData", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetDeclaration(localize.Declaration(-1)); localize.ErrInvalidDeclaration != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidDeclaration, err)
+	}
+}
+
+// TestGlobalObject ensures that the global variable can be
+// assigned as a property of a global object.
+func TestGlobalObject(t *testing.T) {
+	objectCases := map[string]string{
+		"":           "_localData = {",
+		"window":     "window._localData = {",
+		"globalThis": "globalThis._localData = {",
+		"self":       "self._localData = {",
+	}
+	for object, expected := range objectCases {
+		m, err := localize.NewMap("_localData", localize.Data{
+			"int": 1954,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetGlobalObject(object); nil != err {
+			t.Fatalf("Failed to set global object,\nerr: %v\n", err)
+		}
+
+		if output := string(m.JS()); !strings.HasPrefix(output, expected) {
+			t.Run(object, func(t *testing.T) {
+				t.Errorf("Expected output to start with: %q,\ngot: %q\n", expected, output)
+			})
+		}
+	}
+
+	m, err := localize.NewMap("_localData", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetGlobalObject("2window"); localize.ErrInvalidVariableName != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidVariableName, err)
+	}
+	if err := m.SetGlobalObject("window"); nil != err {
+		t.Fatalf("Failed to set global object,\nerr: %v\n", err)
+	}
+	if err := m.SetDeclaration(localize.DeclarationConst); localize.ErrIncompatibleOptions != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrIncompatibleOptions, err)
+	}
+}
+
+// TestExport ensures that the data can be exported from an ES
+// module.
+func TestExport(t *testing.T) {
+	m, err := localize.NewMap("_localData", localize.Data{
+		"int": 1954,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+
+	if err := m.SetDeclaration(localize.DeclarationExportConst); nil != err {
+		t.Fatalf("Failed to set declaration,\nerr: %v\n", err)
+	}
+	expected := "export const _localData = {\n\"int\":1954\n};"
+	if output := string(m.JS()); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	if err := m.SetDeclaration(localize.DeclarationExportDefault); nil != err {
+		t.Fatalf("Failed to set declaration,\nerr: %v\n", err)
+	}
+	expected = "export default {\n\"int\":1954\n};"
+	if output := string(m.JS()); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	// No variable is needed for a default export, so the name
+	// isn't validated, until switching to another declaration.
+	if err := m.SetGlobalName(""); nil != err {
+		t.Errorf("Expected no error,\ngot: %v\n", err)
+	}
+	if output := string(m.JS()); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+	if err := m.SetDeclaration(localize.DeclarationExportConst); localize.ErrInvalidVariableName != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidVariableName, err)
+	}
+}
+
+// TestCommonJS ensures that the data can be exported from a
+// CommonJS module.
+func TestCommonJS(t *testing.T) {
+	m, err := localize.NewMap("_localData", localize.Data{
+		"int": 1954,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetDeclaration(localize.DeclarationCommonJS); nil != err {
+		t.Fatalf("Failed to set declaration,\nerr: %v\n", err)
+	}
+	if err := m.SetGlobalName("not-a-variable"); nil != err {
+		t.Errorf("Expected no error,\ngot: %v\n", err)
+	}
+
+	output := string(m.JS())
+	if !strings.HasPrefix(output, "module.exports = {") || !strings.HasSuffix(output, "};") {
+		t.Errorf("Expected a module.exports assignment,\ngot: %q\n", output)
+	}
+	if strings.Contains(output, "not-a-variable") {
+		t.Errorf("Expected the global name to be left out,\ngot: %q\n", output)
+	}
+}
+
+// TestDottedGlobalName ensures that the data can be assigned
+// into an existing namespace object.
+func TestDottedGlobalName(t *testing.T) {
+	m, err := localize.NewMap("App.config", localize.Data{
+		"int": 1954,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if output := string(m.JS()); !strings.HasPrefix(output, "App.config = {") {
+		t.Errorf("Expected output to start with: %q,\ngot: %q\n", "App.config = {", output)
+	}
+	if err := m.SetDeclaration(localize.DeclarationConst); localize.ErrIncompatibleOptions != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrIncompatibleOptions, err)
+	}
+
+	invalidCases := map[string]error{
+		"App..config": localize.ErrInvalidVariableName,
+		"2App.x":      localize.ErrInvalidVariableName,
+		"App.":        localize.ErrInvalidVariableName,
+		".config":     localize.ErrInvalidVariableName,
+		"App.a[0]":    localize.ErrInvalidVariableName,
+		"App.x`1`":    localize.ErrInvalidVariableName,
+		"App.class":   localize.ErrReservedKeyword,
+	}
+	for name, expected := range invalidCases {
+		if _, err := localize.NewMap(name, localize.Data{}); expected != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", expected, err)
+			})
+		}
+	}
+}
+
+// TestGlobalNameBracketed ensures that global names which aren't
+// valid identifiers can be assigned as computed properties.
+func TestGlobalNameBracketed(t *testing.T) {
+	bracketCases := map[string]string{
+		"app-data":    `window["app-data"] = {`,
+		`say "hi"`:    `window["say \"hi\""] = {`,
+		"</script>":   `window["\u003c/script\u003e"] = {`,
+		"ordinaryKey": `window["ordinaryKey"] = {`,
+	}
+	for key, expected := range bracketCases {
+		m, err := localize.NewMap("_localData", localize.Data{
+			"int": 1954,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetGlobalNameBracketed("window", key); nil != err {
+			t.Fatalf("Failed to set global name,\nerr: %v\n", err)
+		}
+
+		if output := string(m.JS()); !strings.HasPrefix(output, expected) {
+			t.Run(key, func(t *testing.T) {
+				t.Errorf("Expected output to start with: %q,\ngot: %q\n", expected, output)
+			})
+		}
+	}
+
+	m, err := localize.NewMap("_localData", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetGlobalNameBracketed("2window", "app-data"); localize.ErrInvalidVariableName != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidVariableName, err)
+	}
+
+	// The bracketed key isn't validated as a variable name by
+	// declarations, and round-trips through Options.
+	if err := m.SetGlobalNameBracketed("window", "app-data"); nil != err {
+		t.Fatalf("Failed to set global name,\nerr: %v\n", err)
+	}
+	if err := m.SetDeclaration(localize.DeclarationNone); nil != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+	}
+	if err := m.SetDeclaration(localize.DeclarationConst); localize.ErrIncompatibleOptions != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrIncompatibleOptions, err)
+	}
+	c, err := localize.NewMapWithOptions(m.GetGlobalName(), m.GetData(), m.GetOptions())
+	if nil != err {
+		t.Fatalf("Failed to create new map with options,\nerr: %v\n", err)
+	}
+	if expected, output := m.JS(), c.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// TestCallback ensures that the data can be passed to a JSONP
+// callback, rather than assigned to the global variable.
+func TestCallback(t *testing.T) {
+	callbackCases := map[string]template.JS{
+		"myCallback":      "myCallback({\n\"int\":1954,\n});",
+		"jQuery.callback": "jQuery.callback({\n\"int\":1954,\n});",
+	}
+	for callback, expected := range callbackCases {
+		m, err := localize.NewMap("_localData", localize.Data{
+			"int": 1954,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetCallback(callback); nil != err {
+			t.Fatalf("Failed to set callback,\nerr: %v\n", err)
+		}
+
+		if output := m.JS(); expected != output {
+			t.Run(callback, func(t *testing.T) {
+				t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+			})
+		}
+	}
+
+	m, err := localize.NewMap("_localData", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	invalidCases := map[string]error{
+		"my-callback": localize.ErrInvalidVariableName,
+		"2callback":   localize.ErrInvalidVariableName,
+		"alert`1`":    localize.ErrInvalidVariableName,
+		"a[0]":        localize.ErrInvalidVariableName,
+		"a^b":         localize.ErrInvalidVariableName,
+		"delete":      localize.ErrReservedKeyword,
+	}
+	for callback, expected := range invalidCases {
+		if err := m.SetCallback(callback); expected != err {
+			t.Run(callback, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", expected, err)
+			})
+		}
+	}
+
+	// Callbacks don't declare or assign anything.
+	if err := m.SetCallback("myCallback"); nil != err {
+		t.Fatalf("Failed to set callback,\nerr: %v\n", err)
+	}
+	if err := m.SetDeclaration(localize.DeclarationConst); localize.ErrIncompatibleOptions != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrIncompatibleOptions, err)
+	}
+	if err := m.SetGlobalObject("window"); localize.ErrIncompatibleOptions != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrIncompatibleOptions, err)
+	}
+
+	// Clearing the callback restores the assignment.
+	if err := m.SetCallback(""); nil != err {
+		t.Fatalf("Failed to clear callback,\nerr: %v\n", err)
+	}
+	if expected := template.JS("_localData = {};"); expected != m.JS() {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, m.JS())
+	}
+}
+
+// TestOmitSemicolon ensures that the trailing semicolon is only
+// left out when requested.
+func TestOmitSemicolon(t *testing.T) {
+	semicolonCases := map[string]struct {
+		Omit     bool
+		Expected string
+	}{
+		"kept":    {false, "\n};"},
+		"omitted": {true, "\n}"},
+	}
+	for name, tCase := range semicolonCases {
+		m, err := localize.NewMap("_localData", localize.Data{
+			"int": 1954,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		m.SetOmitSemicolon(tCase.Omit)
+
+		if output := string(m.JS()); !strings.HasSuffix(output, tCase.Expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to end with: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+}
+
+// TestFreeze ensures that the data is wrapped in Object.freeze
+// only when enabled.
+func TestFreeze(t *testing.T) {
+	m, err := localize.NewMap("_localData", localize.Data{
+		"int": 1954,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if output := string(m.JS()); strings.Contains(output, "Object.freeze(") {
+		t.Errorf("Expected output not to contain: %q,\ngot: %q\n", "Object.freeze(", output)
+	}
+
+	m.SetFreeze(true)
+	if err := m.SetDeclaration(localize.DeclarationConst); nil != err {
+		t.Fatalf("Failed to set declaration,\nerr: %v\n", err)
+	}
+	expected := template.JS("const _localData = Object.freeze({\n\"int\":1954,\n});")
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+	// Minified default exports keep the space before the call.
+	m, err = localize.NewMap("", localize.Data{"a": 1},
+		localize.WithDeclaration(localize.DeclarationExportDefault),
+		localize.WithMinify(true),
+		localize.WithFreeze(true),
+	)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	expected = template.JS(`export default Object.freeze({"a":1,});`)
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+}
@@ -0,0 +1,146 @@
+/**
+ * escape_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/foresthoffman/localize"
+)
+
+// TestStringEscaping ensures that string values containing
+// quotes, backslashes, and line breaks are written as valid
+// string literals.
+func TestStringEscaping(t *testing.T) {
+	escapeCases := map[string]string{
+		"quotes":     `He said "hi"`,
+		"backslash":  `C:\Users\forest`,
+		"newline":    "first line\nsecond line",
+		"return":     "carriage\rreturn",
+		"tab":        "tabbed\tvalue",
+		"everything": "\"\\\n\r\t",
+	}
+	for name, input := range escapeCases {
+		m, err := localize.NewMap("escapeCase", localize.Data{
+			"strings": map[string]string{
+				"title": input,
+			},
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		output := string(m.JS())
+
+		// Pulls out the string literal and decodes it, which
+		// only succeeds if the literal was properly escaped.
+		start := strings.Index(output, `"title":`) + len(`"title":`)
+		end := strings.Index(output[start:], ",\n") + start
+		var decoded string
+		if err := json.Unmarshal([]byte(output[start:end]), &decoded); nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Failed to parse string literal: %v,\nerr: %v\n", output[start:end], err)
+			})
+			continue
+		}
+		if input != decoded {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %q,\ngot: %q\n", input, decoded)
+			})
+		}
+	}
+}
+
+// TestScriptBreakout ensures that string values can't close the
+// script element that the localized data is placed in.
+func TestScriptBreakout(t *testing.T) {
+	m, err := localize.NewMap("breakoutCase", localize.Data{
+		"payload": map[string]string{
+			"html": "<script>alert(1)</script>",
+			"amp":  "Tom & Jerry",
+		},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	for _, str := range []string{"</script>", "<script>", "<", ">", "&"} {
+		if strings.Contains(output, str) {
+			t.Errorf("Expected output not to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+	expected := []string{
+		`"html":"\u003cscript\u003ealert(1)\u003c/script\u003e",`,
+		`"amp":"Tom \u0026 Jerry",`,
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// TestControlCharacters ensures that control characters are
+// written as unicode escapes, rather than passing through raw.
+func TestControlCharacters(t *testing.T) {
+	m, err := localize.NewMap("controlCase", localize.Data{
+		"payload": map[string]string{
+			"signal": "nul\x00bell\x07tab\x0b",
+		},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	for _, str := range []string{"\x00", "\x07", "\x0b"} {
+		if strings.Contains(output, str) {
+			t.Errorf("Expected output not to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+	expected := `"signal":"nul\u0000bell\u0007tab\u000b",`
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// TestEscapeHTML ensures that the HTML-significant characters are
+// only left unescaped when escaping is disabled, while strings are
+// still written as valid string literals.
+func TestEscapeHTML(t *testing.T) {
+	escapeCases := map[string]struct {
+		Escape   bool
+		Expected string
+	}{
+		"escaped":   {true, `"snippet":"\u003cb\u003e\"bold\"\u003c/b\u003e",`},
+		"unescaped": {false, `"snippet":"<b>\"bold\"</b>",`},
+	}
+	for name, tCase := range escapeCases {
+		m, err := localize.NewMap("escapeCase", localize.Data{
+			"snippet": `<b>"bold"</b>`,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		m.SetEscapeHTML(tCase.Escape)
+		if tCase.Escape != m.GetEscapeHTML() {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %v,\ngot: %v\n", tCase.Escape, m.GetEscapeHTML())
+			})
+		}
+
+		if output := string(m.JS()); !strings.Contains(output, tCase.Expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to contain: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/**
+ * fast_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/foresthoffman/localize"
+)
+
+// Named types don't match the fast path, so they're localized by
+// reflecting over each of their elements.
+type (
+	stringMap   map[string]string
+	intMap      map[string]int
+	boolMap     map[string]bool
+	stringSlice []string
+	intSlice    []int
+)
+
+// TestFastPath ensures that the common container types are
+// localized the same by the fast path as by the reflection path,
+// under the options that affect their output.
+func TestFastPath(t *testing.T) {
+	fast := localize.Data{
+		"strings": map[string]string{"b": "<b>", "a": "\"quoted\"", "": "empty"},
+		"ints":    map[string]int{"ten": 10, "two": 2, "big": 1 << 60},
+		"bools":   map[string]bool{"yes": true, "no": false},
+		"list":    []string{"one", "two"},
+		"numbers": []int{-1, 0, 1 << 60},
+		"empty":   map[string]string{},
+		"none":    []int(nil),
+	}
+	slow := localize.Data{
+		"strings": stringMap{"b": "<b>", "a": "\"quoted\"", "": "empty"},
+		"ints":    intMap{"ten": 10, "two": 2, "big": 1 << 60},
+		"bools":   boolMap{"yes": true, "no": false},
+		"list":    stringSlice{"one", "two"},
+		"numbers": intSlice{-1, 0, 1 << 60},
+		"empty":   stringMap{},
+		"none":    intSlice(nil),
+	}
+	optionCases := map[string]localize.Options{
+		"default":  {},
+		"strict":   {Strict: true},
+		"indent":   {Indent: "\t"},
+		"minify":   {Minify: true},
+		"intPol":   {IntPolicy: localize.IntPolicyString},
+		"noEscape": {NoEscapeHTML: true},
+	}
+	for name, opts := range optionCases {
+		fastMap, err := localize.NewMapWithOptions("fastCase", fast, opts)
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		slowMap, err := localize.NewMapWithOptions("fastCase", slow, opts)
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if expected, output := slowMap.JS(), fastMap.JS(); expected != output {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+			})
+		}
+	}
+}
+
+// benchmarkJS localizes the element repeatedly.
+func benchmarkJS(b *testing.B, element interface{}) {
+	m, err := localize.NewMap("benchCase", localize.Data{
+		"element": element,
+	})
+	if nil != err {
+		b.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.JS()
+	}
+}
+
+// stringEntries generates a map with n string entries.
+func stringEntries(n int) map[string]string {
+	entries := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		entries["key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
+	}
+	return entries
+}
+
+// BenchmarkStringMapFast localizes a map[string]string with 1000
+// entries by the fast path.
+func BenchmarkStringMapFast(b *testing.B) {
+	benchmarkJS(b, stringEntries(1000))
+}
+
+// BenchmarkStringMapReflect localizes the same entries by the
+// reflection path, for comparison.
+func BenchmarkStringMapReflect(b *testing.B) {
+	benchmarkJS(b, stringMap(stringEntries(1000)))
+}
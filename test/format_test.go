@@ -0,0 +1,151 @@
+/**
+ * format_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/foresthoffman/localize"
+)
+
+// nestedData is shared by the layout tests.
+var nestedData = localize.Data{
+	"motd": "Hello world!",
+	"nonce": map[string]interface{}{
+		"login": "LaKJIIjIOUhjbKHdBJHGkhg",
+		"ids":   []int{1, 2},
+	},
+}
+
+// TestIndent ensures that indented output is nested to the
+// correct depth, with and without strict mode.
+func TestIndent(t *testing.T) {
+	indentCases := map[string]struct {
+		Strict   bool
+		Expected template.JS
+	}{
+		"strict": {true, template.JS(`indentCase = {
+  "motd": "Hello world!",
+  "nonce": {
+    "ids": [
+      1,
+      2
+    ],
+    "login": "LaKJIIjIOUhjbKHdBJHGkhg"
+  }
+};`)},
+		"trailingCommas": {false, template.JS(`indentCase = {
+  "motd": "Hello world!",
+  "nonce": {
+    "ids": [
+      1,
+      2,
+    ],
+    "login": "LaKJIIjIOUhjbKHdBJHGkhg",
+  },
+};`)},
+	}
+	for name, tCase := range indentCases {
+		m, err := localize.NewMap("indentCase", nestedData)
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		m.SetStrict(tCase.Strict)
+		m.SetIndent("", "  ")
+
+		if output := m.JS(); tCase.Expected != output {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+
+	// Clearing the indent restores the default layout.
+	m, err := localize.NewMap("intCase", testCases["intCase"].Input)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetIndent("", "  ")
+	m.ClearIndent()
+	if output := m.JS(); testCases["intCase"].Expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", testCases["intCase"].Expected, output)
+	}
+}
+
+// TestMinify ensures that minified output leaves out all of the
+// whitespace, without changing the data.
+func TestMinify(t *testing.T) {
+	m, err := localize.NewMap("minifyCase", nestedData)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	full := m.JS()
+	m.SetMinify(true)
+	minified := m.JS()
+
+	expected := template.JS(`minifyCase={"motd":"Hello world!","nonce":{"ids":[1,2,],"login":"LaKJIIjIOUhjbKHdBJHGkhg",},};`)
+	if expected != minified {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, minified)
+	}
+	if len(minified) >= len(full) {
+		t.Errorf("Expected minified output to be shorter than %v bytes,\ngot: %v\n", len(full), len(minified))
+	}
+
+	// Minified strict output decodes to the same data.
+	m.SetStrict(true)
+	minifiedJSON, err := m.JSON()
+	if nil != err {
+		t.Fatalf("Failed to get JSON,\nerr: %v\n", err)
+	}
+	m.SetMinify(false)
+	fullJSON, err := m.JSON()
+	if nil != err {
+		t.Fatalf("Failed to get JSON,\nerr: %v\n", err)
+	}
+	var minifiedData, fullData interface{}
+	if err := json.Unmarshal(minifiedJSON, &minifiedData); nil != err {
+		t.Fatalf("Failed to decode JSON: %q,\nerr: %v\n", minifiedJSON, err)
+	}
+	if err := json.Unmarshal(fullJSON, &fullData); nil != err {
+		t.Fatalf("Failed to decode JSON: %q,\nerr: %v\n", fullJSON, err)
+	}
+	if !reflect.DeepEqual(fullData, minifiedData) {
+		t.Errorf("Expected: %v,\ngot: %v\n", fullData, minifiedData)
+	}
+}
+
+// TestNewline ensures that every line of the output ends with the
+// chosen line break.
+func TestNewline(t *testing.T) {
+	m, err := localize.NewMap("newlineCase", nestedData)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetNewline(localize.NewlineCRLF); nil != err {
+		t.Fatalf("Failed to set newline,\nerr: %v\n", err)
+	}
+	m.SetIndent("", "\t")
+
+	expected := template.JS("newlineCase = {\r\n\t\"motd\": \"Hello world!\",\r\n\t\"nonce\": {\r\n\t\t\"ids\": [\r\n\t\t\t1,\r\n\t\t\t2,\r\n\t\t],\r\n\t\t\"login\": \"LaKJIIjIOUhjbKHdBJHGkhg\",\r\n\t},\r\n};")
+	output := m.JS()
+	if expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+	if bare := strings.Count(string(output), "\n") - strings.Count(string(output), "\r\n"); 0 != bare {
+		t.Errorf("Expected no bare line feeds,\ngot: %v\n", bare)
+	}
+
+	if err := m.SetNewline(localize.Newline(-1)); localize.ErrInvalidNewline != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidNewline, err)
+	}
+}
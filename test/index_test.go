@@ -9,15 +9,23 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"html/template"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"unsafe"
 
 	"github.com/foresthoffman/localize"
 )
 
 type testCase struct {
 	Input    localize.Data
-	Expected []template.JS
+	Expected template.JS
 }
 
 var testCases = map[string]testCase{
@@ -26,29 +34,22 @@ var testCases = map[string]testCase{
 		Input: localize.Data{
 			"int": 1954,
 		},
-		Expected: []template.JS{template.JS(
+		Expected: template.JS(
 			`intCase = {
-"int": [
-1954,
-],
-
+"int":1954,
 };`,
-		)},
+		),
 	},
 	// Int array case.
 	"intArrayCase": testCase{
 		Input: localize.Data{
 			"intArray": []int{1, 2, 3, 4, 5},
 		},
-		Expected: []template.JS{template.JS(
+		Expected: template.JS(
 			`intArrayCase = {
-"intArray": [
-[1,2,3,4,5,],
-
-],
-
+"intArray":[1,2,3,4,5,],
 };`,
-		)},
+		),
 	},
 	// Multi-dimensional array case.
 	"multiArrayCase": testCase{
@@ -58,17 +59,11 @@ var testCases = map[string]testCase{
 				[]int{11, 12, 13, 14, 15},
 			},
 		},
-		Expected: []template.JS{template.JS(
+		Expected: template.JS(
 			`multiArrayCase = {
-"arrayArray": [
-[[6,7,8,9,10,],
-[11,12,13,14,15,],
-],
-
-],
-
+"arrayArray":[[6,7,8,9,10,],[11,12,13,14,15,],],
 };`,
-		)},
+		),
 	},
 	// Map case.
 	"mapCase": testCase{
@@ -78,28 +73,14 @@ var testCases = map[string]testCase{
 				"foo": "bar",
 			},
 		},
-		Expected: []template.JS{
-			template.JS(
-				`mapCase = {
-"assocArray": {
+		Expected: template.JS(
+			`mapCase = {
+"assocArray":{
 "baz":"fubar",
 "foo":"bar",
-
 },
-
 };`,
-			),
-			template.JS(
-				`mapCase = {
-"assocArray": {
-"foo":"bar",
-"baz":"fubar",
-
-},
-
-};`,
-			),
-		},
+		),
 	},
 }
 var maps = make(map[string]*localize.Map)
@@ -122,18 +103,9 @@ func TestNewMap(t *testing.T) {
 // expected.
 func TestJS(t *testing.T) {
 	for name, m := range maps {
-		output := m.JS()
-
-		matched := false
-		for _, expected := range testCases[name].Expected {
-			if expected == output {
-				matched = true
-				break
-			}
-		}
-		if !matched {
+		if output := m.JS(); testCases[name].Expected != output {
 			t.Run(name, func(t *testing.T) {
-				t.Errorf("Expected one of: %v,\ngot: %q\n", testCases[name].Expected, output)
+				t.Errorf("Expected: %q,\ngot: %q\n", testCases[name].Expected, output)
 			})
 		}
 	}
@@ -173,3 +145,875 @@ func TestReservedVariableName(t *testing.T) {
 		}
 	}
 }
+
+// TestJSWithError ensures that values which can't be localized
+// are reported, along with the key that holds them.
+func TestJSWithError(t *testing.T) {
+	m, err := localize.NewMap("errorCase", localize.Data{
+		"settings": map[string]interface{}{
+			"updates": make(chan int),
+		},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	output, err := m.JSWithError()
+	if nil == err {
+		t.Fatalf("Expected an error for the unsupported channel,\ngot: %q\n", output)
+	}
+	for _, str := range []string{"chan", "settings.updates"} {
+		if !strings.Contains(err.Error(), str) {
+			t.Errorf("Expected error to mention: %q,\ngot: %v\n", str, err)
+		}
+	}
+	if m.JS() != output {
+		t.Errorf("Expected JS() to match JSWithError(),\nexpected: %q,\ngot: %q\n", output, m.JS())
+	}
+
+	// Supported data shouldn't produce an error.
+	for name, m := range maps {
+		if _, err := m.JSWithError(); nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected no error,\ngot: %v\n", err)
+			})
+		}
+	}
+}
+
+// TestUnsupportedKinds ensures that values without a JavaScript
+// equivalent are detected, rather than silently dropped.
+func TestUnsupportedKinds(t *testing.T) {
+	unsupportedCases := map[string]struct {
+		Input    interface{}
+		Expected string
+	}{
+		"chan":    {make(chan int), "\"value\":null,"},
+		"func":    {func() {}, "\"value\":null,"},
+		"pointer": {unsafe.Pointer(nil), "\"value\":null,"},
+		"mapKey":  {map[struct{}]int{{}: 1}, "\"value\":{},"},
+	}
+	for name, tCase := range unsupportedCases {
+		m, err := localize.NewMap("unsupportedCase", localize.Data{
+			"value": tCase.Input,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		output, err := m.JSWithError()
+		if !errors.Is(err, localize.ErrUnsupportedKind) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrUnsupportedKind, err)
+			})
+			continue
+		}
+		if !strings.Contains(string(output), tCase.Expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to contain: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+}
+
+// TestConcurrentAccess ensures that a map can be modified and
+// localized from several goroutines at once. Run with -race to
+// detect unsynchronized access.
+func TestConcurrentAccess(t *testing.T) {
+	m, err := localize.NewMap("concurrentCase", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("key%d", i)
+				if err := m.Add(key, j); nil != err {
+					t.Errorf("Failed to add element,\nerr: %v\n", err)
+				}
+				if 0 == j%10 {
+					m.Delete(key)
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.JS()
+				m.GetGlobalName()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestConcurrentOutput ensures that maps localized from several
+// goroutines at once each get their own output, even though the
+// buffers that it's built in are reused.
+func TestConcurrentOutput(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("outputCase%d", i)
+		m, err := localize.NewMap(name, localize.Data{
+			"id":   i,
+			"tags": []string{strings.Repeat("x", i*100)},
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		expected := m.JS()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var outputs []template.JS
+			for j := 0; j < 100; j++ {
+				outputs = append(outputs, m.JS())
+			}
+			for _, output := range outputs {
+				if expected != output {
+					t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestGetDataCopy ensures that modifying the data returned by
+// GetData doesn't modify the map itself.
+func TestGetDataCopy(t *testing.T) {
+	m, err := localize.NewMap("copyCase", localize.Data{
+		"motd": "Hello world!",
+		"int":  1954,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	data := m.GetData()
+	for key := range data {
+		delete(data, key)
+	}
+	data["added"] = true
+
+	data = m.GetData()
+	if 2 != len(data) || "Hello world!" != data["motd"] || 1954 != data["int"] {
+		t.Errorf("Expected the original data to be unaffected,\ngot: %v\n", data)
+	}
+	if _, ok := data["added"]; ok {
+		t.Errorf("Expected the added key to be left out,\ngot: %v\n", data)
+	}
+}
+
+// TestAddMany ensures that several elements can be added at
+// once, and that none are added when one of them is invalid.
+func TestAddMany(t *testing.T) {
+	m, err := localize.NewMap("addManyCase", localize.Data{
+		"motd": "Hello world!",
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	err = m.AddMany(localize.Data{
+		"int":   1954,
+		"float": 2.5,
+	})
+	if nil != err {
+		t.Fatalf("Failed to add elements,\nerr: %v\n", err)
+	}
+	if data := m.GetData(); 3 != len(data) || 1954 != data["int"] || 2.5 != data["float"] {
+		t.Errorf("Expected all elements to be added,\ngot: %v\n", data)
+	}
+
+	err = m.AddMany(localize.Data{
+		"alpha": "a",
+		"":      "empty",
+		"omega": "z",
+	})
+	if !errors.Is(err, localize.ErrInvalidKey) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidKey, err)
+	}
+	if nil != err && !strings.Contains(err.Error(), `""`) {
+		t.Errorf("Expected error to name the invalid key,\ngot: %v\n", err)
+	}
+	if data := m.GetData(); 3 != len(data) {
+		t.Errorf("Expected no elements to be added,\ngot: %v\n", data)
+	}
+
+	err = m.AddMany(localize.Data{
+		"alpha": "a",
+		"nil":   nil,
+	})
+	if nil != err {
+		t.Errorf("Expected nil elements to be added,\ngot: %v\n", err)
+	}
+}
+
+// TestSetData ensures that the data map can be replaced
+// wholesale, and that invalid data leaves it unchanged.
+func TestSetData(t *testing.T) {
+	m, err := localize.NewMap("setDataCase", localize.Data{
+		"motd": "Hello world!",
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	if err := m.SetData(localize.Data{"int": 1954, "nil": nil}); nil != err {
+		t.Fatalf("Failed to set data,\nerr: %v\n", err)
+	}
+	if keys := m.Keys(); !reflect.DeepEqual([]string{"int", "nil"}, keys) {
+		t.Errorf("Expected: %v,\ngot: %v\n", []string{"int", "nil"}, keys)
+	}
+
+	err = m.SetData(localize.Data{"motd": "Goodbye!", "": 1954})
+	if !errors.Is(err, localize.ErrInvalidKey) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidKey, err)
+	}
+	if keys := m.Keys(); !reflect.DeepEqual([]string{"int", "nil"}, keys) {
+		t.Errorf("Expected the data to be unchanged: %v,\ngot: %v\n", []string{"int", "nil"}, keys)
+	}
+
+	if err := m.SetData(nil); nil != err {
+		t.Fatalf("Failed to set data,\nerr: %v\n", err)
+	}
+	if 0 != m.Len() {
+		t.Errorf("Expected: %v,\ngot: %v\n", 0, m.Len())
+	}
+	if err := m.Add("int", 1955); nil != err {
+		t.Errorf("Failed to add element,\nerr: %v\n", err)
+	}
+}
+
+// TestStrictKeys ensures that keys are validated as JavaScript
+// identifiers only under StrictKeys.
+func TestStrictKeys(t *testing.T) {
+	keyCases := map[string]bool{
+		"motd":    true,
+		"_nonce":  true,
+		"$el":     true,
+		"user2":   true,
+		"foo bar": false,
+		"2foo":    false,
+		"foo-bar": false,
+		"a[0]":    false,
+		"a`b":     false,
+		"a^b":     false,
+		"":        false,
+	}
+	for key, valid := range keyCases {
+		m, err := localize.NewMap("strictKeysCase", localize.Data{})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+
+		// Only the empty key is rejected by default.
+		if err := m.Add(key, 1954); ("" != key) != (nil == err) {
+			t.Run(key, func(t *testing.T) {
+				t.Errorf("Expected the key to be accepted by default,\ngot: %v\n", err)
+			})
+		}
+
+		m.SetStrictKeys(true)
+		err = m.Add(key, 1954)
+		if valid && nil != err {
+			t.Run(key, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+			})
+		}
+		if !valid && !errors.Is(err, localize.ErrInvalidKey) {
+			t.Run(key, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidKey, err)
+			})
+		}
+		if err := m.AddMany(localize.Data{key: 1954}); !valid && !errors.Is(err, localize.ErrInvalidKey) {
+			t.Run(key, func(t *testing.T) {
+				t.Errorf("Expected AddMany to reject the key,\ngot: %v\n", err)
+			})
+		}
+	}
+}
+
+// TestStrictReservedKeys ensures that reserved keywords are only
+// rejected as keys under StrictKeys.
+func TestStrictReservedKeys(t *testing.T) {
+	for _, key := range []string{"delete", "class", "default", "new"} {
+		m, err := localize.NewMap("strictReservedCase", localize.Data{})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.Add(key, 1954); nil != err {
+			t.Run(key, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+			})
+		}
+
+		m.SetStrictKeys(true)
+		if err := m.Add(key, 1954); !errors.Is(err, localize.ErrReservedKeyword) || !strings.Contains(err.Error(), `"`+key+`"`) {
+			t.Run(key, func(t *testing.T) {
+				t.Errorf("Expected err: %v, naming the key,\ngot: %v\n", localize.ErrReservedKeyword, err)
+			})
+		}
+	}
+}
+
+// TestAddNil ensures that nil elements are accepted, and that
+// they're localized as null.
+func TestAddNil(t *testing.T) {
+	m, err := localize.NewMap("addNilCase", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.Add("user", nil); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+
+	expected := template.JS("addNilCase = {\n\"user\":null\n};")
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+	if err := m.Add("", nil); !errors.Is(err, localize.ErrInvalidKey) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidKey, err)
+	}
+}
+
+// TestAddUnique ensures that fresh keys are added, and that
+// existing keys are reported rather than overwritten.
+func TestAddUnique(t *testing.T) {
+	m, err := localize.NewMap("uniqueCase", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.AddUnique("theme", "dark"); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+
+	err = m.AddUnique("theme", "light")
+	if !errors.Is(err, localize.ErrDuplicateKey) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrDuplicateKey, err)
+	}
+	if nil != err && !strings.Contains(err.Error(), `key "theme"`) {
+		t.Errorf("Expected error to name the key, %q,\ngot: %v\n", "theme", err)
+	}
+	if theme := m.GetData()["theme"]; "dark" != theme {
+		t.Errorf("Expected: %v,\ngot: %v\n", "dark", theme)
+	}
+	if err := m.AddUnique("", nil); !errors.Is(err, localize.ErrInvalidKey) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidKey, err)
+	}
+}
+
+// TestReset ensures that resetting a map removes its elements,
+// but keeps the global name.
+func TestReset(t *testing.T) {
+	m, err := localize.NewMap("resetCase", localize.Data{
+		"motd": "Hello world!",
+		"int":  1954,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.Reset()
+
+	if 0 != m.Len() {
+		t.Errorf("Expected: %v,\ngot: %v\n", 0, m.Len())
+	}
+	if name := m.GetGlobalName(); "resetCase" != name {
+		t.Errorf("Expected: %q,\ngot: %q\n", "resetCase", name)
+	}
+
+	// The map is still usable after being reset.
+	if err := m.Add("int", 1955); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+	expected := template.JS("resetCase = {\n\"int\":1955,\n};")
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// TestClone ensures that a cloned map can be modified without
+// affecting the original, including its nested data.
+func TestClone(t *testing.T) {
+	m, err := localize.NewMap("cloneCase", localize.Data{
+		"motd": "Hello world!",
+		"ids":  []int{1, 2},
+		"nonce": map[string]interface{}{
+			"login": "LaKJIIjIOUhjbKHdBJHGkhg",
+			"tags":  []string{"a"},
+		},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+	expected := m.JS()
+
+	c := m.Clone()
+	if output := c.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	if err := c.SetGlobalName("cloned"); nil != err {
+		t.Fatalf("Failed to set global name,\nerr: %v\n", err)
+	}
+	if err := c.Add("motd", "Goodbye!"); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+	data := c.GetData()
+	data["ids"].([]int)[0] = 100
+	nonce := data["nonce"].(map[string]interface{})
+	nonce["login"] = "changed"
+	nonce["tags"].([]string)[0] = "b"
+
+	if output := m.JS(); expected != output {
+		t.Errorf("Expected the original to be untouched: %q,\ngot: %q\n", expected, output)
+	}
+	if name := m.GetGlobalName(); "cloneCase" != name {
+		t.Errorf("Expected: %q,\ngot: %q\n", "cloneCase", name)
+	}
+
+	// Cyclic data is copied into data referring to the copy.
+	inner := map[string]interface{}{}
+	inner["self"] = inner
+	list := []interface{}{nil}
+	list[0] = list
+	cyclic := localize.Data{"inner": inner, "list": list}
+	cyclic["self"] = cyclic
+	m, err = localize.NewMap("cyclicCloneCase", cyclic)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	data = m.Clone().GetData()
+	pointerCases := map[string]interface{}{
+		"self":  cyclic,
+		"inner": inner,
+		"list":  list,
+	}
+	for key, original := range pointerCases {
+		copied := data[key]
+		var nested interface{}
+		switch value := copied.(type) {
+		case map[string]interface{}:
+			nested = value["self"]
+		case []interface{}:
+			nested = value[0]
+		}
+		copiedPtr := reflect.ValueOf(copied).Pointer()
+		if reflect.ValueOf(original).Pointer() == copiedPtr {
+			t.Errorf("Expected %q to be copied,\ngot the original\n", key)
+		}
+		if reflect.ValueOf(nested).Pointer() != copiedPtr {
+			t.Errorf("Expected %q to refer to its copy\n", key)
+		}
+	}
+}
+
+// TestHas ensures that the existence of keys is reported as
+// expected.
+func TestHas(t *testing.T) {
+	m, err := localize.NewMap("hasCase", localize.Data{
+		"motd": "Hello world!",
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.Add("int", 1954); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+
+	hasCases := map[string]bool{
+		"motd":  true,
+		"int":   true,
+		"nonce": false,
+		"":      false,
+	}
+	for key, expected := range hasCases {
+		if has := m.Has(key); expected != has {
+			t.Run(key, func(t *testing.T) {
+				t.Errorf("Expected: %v,\ngot: %v\n", expected, has)
+			})
+		}
+	}
+}
+
+// TestKeysAndLen ensures that the keys of the map are reported
+// in sorted order, and that they're counted correctly.
+func TestKeysAndLen(t *testing.T) {
+	m, err := localize.NewMap("keysCase", localize.Data{
+		"nonce": "LaKJIIjIOUhjbKHdBJHGkhg",
+		"motd":  "Hello world!",
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.Add("int", 1954); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+	if err := m.Add("bool", true); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+	if err := m.Delete("nonce"); nil != err {
+		t.Fatalf("Failed to delete element,\nerr: %v\n", err)
+	}
+
+	expected := []string{"bool", "int", "motd"}
+	if keys := m.Keys(); !reflect.DeepEqual(expected, keys) {
+		t.Errorf("Expected: %v,\ngot: %v\n", expected, keys)
+	}
+	if length := m.Len(); len(expected) != length {
+		t.Errorf("Expected: %v,\ngot: %v\n", len(expected), length)
+	}
+}
+
+// TestWriteJS ensures that streamed output matches the output of
+// JS.
+func TestWriteJS(t *testing.T) {
+	for name, m := range maps {
+		var buf bytes.Buffer
+		n, err := m.WriteJS(&buf)
+		if nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Failed to write JS,\nerr: %v\n", err)
+			})
+			continue
+		}
+		if buf.Len() != n {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected %v bytes written,\ngot: %v\n", buf.Len(), n)
+			})
+		}
+		if string(m.JS()) != buf.String() {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %q,\ngot: %q\n", m.JS(), buf.String())
+			})
+		}
+	}
+}
+
+// TestAppendTo ensures that several maps can be appended to one
+// buffer, each as a separate assignment.
+func TestAppendTo(t *testing.T) {
+	first, err := localize.NewMap("first", localize.Data{"int": 1})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	second, err := localize.NewMap("second", localize.Data{"int": 2})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	var buf bytes.Buffer
+	for _, m := range []*localize.Map{first, second} {
+		if err := m.AppendTo(&buf); nil != err {
+			t.Fatalf("Failed to append JS,\nerr: %v\n", err)
+		}
+	}
+
+	expected := "first = {\n\"int\":1,\n};\nsecond = {\n\"int\":2,\n};"
+	if expected != buf.String() {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, buf.String())
+	}
+}
+
+// TestCombineJS ensures that maps with distinct global names are
+// combined in order, and that colliding names are rejected.
+func TestCombineJS(t *testing.T) {
+	first, err := localize.NewMap("first", localize.Data{"int": 1})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	second, err := localize.NewMap("second", localize.Data{"int": 2})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	output, err := localize.CombineJS(first, second)
+	if nil != err {
+		t.Fatalf("Failed to combine JS,\nerr: %v\n", err)
+	}
+	expected := template.JS("first = {\n\"int\":1,\n};\nsecond = {\n\"int\":2,\n};")
+	if expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	// The maps' own newline style separates them.
+	for _, m := range []*localize.Map{first, second} {
+		if err := m.SetNewline(localize.NewlineCRLF); nil != err {
+			t.Fatalf("Failed to set newline,\nerr: %v\n", err)
+		}
+	}
+	output, err = localize.CombineJS(first, second)
+	if nil != err {
+		t.Fatalf("Failed to combine JS,\nerr: %v\n", err)
+	}
+	expected = template.JS("first = {\r\n\"int\":1,\r\n};\r\nsecond = {\r\n\"int\":2,\r\n};")
+	if expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+	for _, m := range []*localize.Map{first, second} {
+		m.SetNewline(localize.NewlineLF)
+	}
+
+	duplicate, err := localize.NewMap("first", localize.Data{"int": 3})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if _, err := localize.CombineJS(first, second, duplicate); !errors.Is(err, localize.ErrDuplicateGlobalName) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrDuplicateGlobalName, err)
+	}
+
+	// Maps are compared by what they assign, rather than by name.
+	newMap := func(name string, opts ...localize.Option) *localize.Map {
+		m, err := localize.NewMap(name, localize.Data{"int": 1}, opts...)
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		return m
+	}
+	bracketed := newMap("bracketed")
+	if err := bracketed.SetGlobalNameBracketed("window", "appData"); nil != err {
+		t.Fatalf("Failed to set global name,\nerr: %v\n", err)
+	}
+	collisionCases := map[string][]localize.Localizer{
+		"exportDefault": {
+			newMap("a", localize.WithDeclaration(localize.DeclarationExportDefault)),
+			newMap("b", localize.WithDeclaration(localize.DeclarationExportDefault)),
+		},
+		"commonJS": {
+			newMap("a", localize.WithDeclaration(localize.DeclarationCommonJS)),
+			newMap("b", localize.WithDeclaration(localize.DeclarationCommonJS)),
+		},
+		"globalObject": {
+			newMap("appData", localize.WithGlobalObject("window")),
+			bracketed,
+		},
+	}
+	for name, maps := range collisionCases {
+		if _, err := localize.CombineJS(maps...); !errors.Is(err, localize.ErrDuplicateGlobalName) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrDuplicateGlobalName, err)
+			})
+		}
+	}
+
+	distinctCases := map[string][]localize.Localizer{
+		"exports": {
+			newMap("first", localize.WithDeclaration(localize.DeclarationExportConst)),
+			newMap("", localize.WithDeclaration(localize.DeclarationExportDefault)),
+		},
+		"callbacks": {
+			newMap("first", localize.WithCallback("receive")),
+			newMap("first", localize.WithCallback("receive")),
+		},
+	}
+	for name, maps := range distinctCases {
+		if _, err := localize.CombineJS(maps...); nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+			})
+		}
+	}
+}
+
+// failingWriter rejects every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("Write failed")
+}
+
+// TestWriteJSError ensures that writer errors are reported.
+func TestWriteJSError(t *testing.T) {
+	m, err := localize.NewMap("writerCase", localize.Data{
+		"int": 1954,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if n, err := m.WriteJS(failingWriter{}); nil == err || 0 != n {
+		t.Errorf("Expected an error and 0 bytes written,\ngot: %v, %v\n", n, err)
+	}
+}
+
+// TestJSContext ensures that localization gives up once the
+// context is done.
+func TestJSContext(t *testing.T) {
+	data := localize.Data{}
+	for i := 0; i < 1000; i++ {
+		data[fmt.Sprintf("key%d", i)] = []int{i, i + 1, i + 2}
+	}
+	m, err := localize.NewMap("contextCase", data)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	output, err := m.JSContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", context.Canceled, err)
+	}
+	if "" != output {
+		t.Errorf("Expected no output,\ngot: %q\n", output)
+	}
+
+	// A live context produces the same output as JS.
+	output, err = m.JSContext(context.Background())
+	if nil != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+	}
+	if expected := m.JS(); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += len(p)
+	return len(p), nil
+}
+
+// TestReflectTargetWriters ensures that ReflectTarget can write
+// to any io.Writer.
+func TestReflectTargetWriters(t *testing.T) {
+	target := reflect.ValueOf([]int{1, 2, 3, 4, 5})
+	expected := "[1,2,3,4,5,]"
+
+	var buf bytes.Buffer
+	localize.ReflectTarget(target, &buf)
+	if expected != buf.String() {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, buf.String())
+	}
+
+	counter := &countingWriter{}
+	localize.ReflectTarget(target, counter)
+	if len(expected) != counter.n {
+		t.Errorf("Expected %v bytes written,\ngot: %v\n", len(expected), counter.n)
+	}
+}
+
+// TestSortedKeys ensures that map keys are localized in sorted
+// order, so that the output is the same on every call.
+func TestSortedKeys(t *testing.T) {
+	m, err := localize.NewMap("sortedCase", localize.Data{
+		"delta":   4,
+		"alpha":   1,
+		"charlie": 3,
+		"bravo":   2,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+
+	expected := template.JS("sortedCase = {\n\"alpha\":1,\n\"bravo\":2,\n\"charlie\":3,\n\"delta\":4\n};")
+	for i := 0; i < 20; i++ {
+		if output := m.JS(); expected != output {
+			t.Fatalf("Expected: %q,\ngot: %q\n", expected, output)
+		}
+	}
+}
+
+// TestDeepSortedKeys ensures that the keys of nested maps are
+// also localized in sorted order, at every level.
+func TestDeepSortedKeys(t *testing.T) {
+	m, err := localize.NewMap("deepSortedCase", localize.Data{
+		"zulu": map[string]interface{}{
+			"yankee": map[string]interface{}{
+				"delta": 4,
+				"alpha": 1,
+				"bravo": 2,
+			},
+			"xray": 3,
+		},
+		"alpha": map[string]int{
+			"charlie": 3,
+			"bravo":   2,
+		},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+
+	expected := template.JS(`deepSortedCase = {
+"alpha":{
+"bravo":2,
+"charlie":3
+},
+"zulu":{
+"xray":3,
+"yankee":{
+"alpha":1,
+"bravo":2,
+"delta":4
+}
+}
+};`)
+	for i := 0; i < 20; i++ {
+		if output := m.JS(); expected != output {
+			t.Fatalf("Expected: %q,\ngot: %q\n", expected, output)
+		}
+	}
+}
+
+// TestInvalidElementErrors ensures that invalid elements are
+// reported with the sentinel errors, along with the key.
+func TestInvalidElementErrors(t *testing.T) {
+	m, err := localize.NewMap("invalidElementCase", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	errorCases := map[string]struct {
+		Err      error
+		Sentinel error
+		Key      string
+	}{
+		"addEmpty":    {m.Add("", 1954), localize.ErrInvalidKey, `""`},
+		"deleteEmpty": {m.Delete(""), localize.ErrInvalidKey, `""`},
+	}
+	for name, tCase := range errorCases {
+		if !errors.Is(tCase.Err, tCase.Sentinel) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", tCase.Sentinel, tCase.Err)
+			})
+			continue
+		}
+		if !strings.Contains(tCase.Err.Error(), "key "+tCase.Key) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected error to name the key, %v,\ngot: %v\n", tCase.Key, tCase.Err)
+			})
+		}
+	}
+}
+
+// BenchmarkJS localizes a small map repeatedly, reporting the
+// allocations per call.
+func BenchmarkJS(b *testing.B) {
+	m, err := localize.NewMap("benchCase", localize.Data{
+		"motd":  "Hello world!",
+		"nonce": map[string]string{"login": "LaKJIIjIOUhjbKHdBJHGkhg"},
+		"ids":   []int{1, 2, 3},
+	})
+	if nil != err {
+		b.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.JS()
+	}
+}
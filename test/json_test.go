@@ -0,0 +1,769 @@
+/**
+ * json_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/foresthoffman/localize"
+)
+
+// jsonCases holds data that strict output is expected to
+// round-trip through encoding/json.
+var jsonCases = map[string]localize.Data{
+	"scalarCase": localize.Data{
+		"int":    1954,
+		"float":  2.5,
+		"string": `He said "hi" </script>`,
+		"bool":   true,
+		"null":   nil,
+		"bytes":  []byte("hello"),
+	},
+	"arrayCase": localize.Data{
+		"intArray":   []int{1, 2, 3, 4, 5},
+		"arrayArray": [][]int{[]int{6, 7}, []int{}},
+		"fixedArray": [3]int{1, 2, 3},
+		"fixedGrid":  [2][2]int{{1, 2}, {3, 4}},
+	},
+	"mapCase": localize.Data{
+		"assocArray": map[string]interface{}{
+			"foo": "bar",
+			"baz": map[string]int{"fubar": 1},
+			"qux": map[string]int{},
+		},
+	},
+	"emptyCase": localize.Data{},
+}
+
+// normalize round-trips the data through encoding/json, so that
+// it can be compared against decoded output.
+func normalize(t *testing.T, data localize.Data) interface{} {
+	raw, err := json.Marshal(data)
+	if nil != err {
+		t.Fatalf("Failed to marshal data,\nerr: %v\n", err)
+	}
+	var expected interface{}
+	if err := json.Unmarshal(raw, &expected); nil != err {
+		t.Fatalf("Failed to unmarshal data,\nerr: %v\n", err)
+	}
+	return expected
+}
+
+// TestStrict ensures that strict output can be decoded as JSON
+// once the global assignment is removed.
+func TestStrict(t *testing.T) {
+	for name, data := range jsonCases {
+		m, err := localize.NewMap(name, data)
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		m.SetStrict(true)
+		output := string(m.JS())
+
+		prefix := name + " = "
+		if !strings.HasPrefix(output, prefix) || !strings.HasSuffix(output, ";") {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected an assignment to %v,\ngot: %q\n", name, output)
+			})
+			continue
+		}
+		var decoded interface{}
+		raw := strings.TrimSuffix(strings.TrimPrefix(output, prefix), ";")
+		if err := json.Unmarshal([]byte(raw), &decoded); nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Failed to decode strict output: %q,\nerr: %v\n", raw, err)
+			})
+			continue
+		}
+		if expected := normalize(t, data); !reflect.DeepEqual(expected, decoded) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %v,\ngot: %v\n", expected, decoded)
+			})
+		}
+	}
+}
+
+// TestJSON ensures that the JSON output matches the output of
+// encoding/json for the same data.
+func TestJSON(t *testing.T) {
+	for name, data := range jsonCases {
+		m, err := localize.NewMap(name, data)
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		output, err := m.JSON()
+		if nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Failed to get JSON,\nerr: %v\n", err)
+			})
+			continue
+		}
+
+		var decoded interface{}
+		if err := json.Unmarshal(output, &decoded); nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Failed to decode JSON: %q,\nerr: %v\n", output, err)
+			})
+			continue
+		}
+		if expected := normalize(t, data); !reflect.DeepEqual(expected, decoded) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %v,\ngot: %v\n", expected, decoded)
+			})
+		}
+	}
+
+	// Data that can't be localized shouldn't produce output.
+	m, err := localize.NewMap("invalidCase", localize.Data{
+		"updates": make(chan int),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if output, err := m.JSON(); nil == err || nil != output {
+		t.Errorf("Expected an error and no output,\ngot: %q, %v\n", output, err)
+	}
+}
+
+// taggedUser uses "json" struct tags to rename and skip fields.
+type taggedUser struct {
+	UserID   int    `json:"user_id"`
+	Name     string `json:"name,omitempty"`
+	Password string `json:"-"`
+	Dash     string `json:"-,"`
+	Email    string
+}
+
+// TestStructTags ensures that struct fields are named according
+// to their "json" struct tags.
+func TestStructTags(t *testing.T) {
+	data := localize.Data{
+		"user": taggedUser{
+			UserID:   7,
+			Name:     "Forest",
+			Password: "hunter2",
+			Dash:     "dash",
+			Email:    "forest@example.com",
+		},
+	}
+	m, err := localize.NewMap("tagCase", data)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+	output := string(m.JS())
+
+	if strings.Contains(output, "hunter2") || strings.Contains(output, "Password") {
+		t.Errorf("Expected skipped field to be left out,\ngot: %q\n", output)
+	}
+	var decoded interface{}
+	raw := strings.TrimSuffix(strings.TrimPrefix(output, "tagCase = "), ";")
+	if err := json.Unmarshal([]byte(raw), &decoded); nil != err {
+		t.Fatalf("Failed to decode strict output: %q,\nerr: %v\n", raw, err)
+	}
+	if expected := normalize(t, data); !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected: %v,\ngot: %v\n", expected, decoded)
+	}
+}
+
+// session mixes exported and unexported fields.
+type session struct {
+	ID     string
+	secret string
+	Active bool
+	token  *string
+}
+
+// TestKeyCase ensures that the Go names of struct fields are
+// converted to the key case, while tag names are kept.
+func TestKeyCase(t *testing.T) {
+	type account struct {
+		UserID     int
+		HTTPServer string
+		Version2   bool
+		Tagged     string `json:"Tagged_Name"`
+	}
+	keyCases := map[string]struct {
+		KeyCase  localize.KeyCase
+		Expected []string
+	}{
+		"none":  {localize.KeyCaseNone, []string{"UserID", "HTTPServer", "Version2", "Tagged_Name"}},
+		"camel": {localize.KeyCaseCamel, []string{"userID", "httpServer", "version2", "Tagged_Name"}},
+		"snake": {localize.KeyCaseSnake, []string{"user_id", "http_server", "version2", "Tagged_Name"}},
+		"kebab": {localize.KeyCaseKebab, []string{"user-id", "http-server", "version2", "Tagged_Name"}},
+	}
+	for name, tCase := range keyCases {
+		m, err := localize.NewMap("keyCase", localize.Data{
+			"account": account{UserID: 7},
+		}, localize.WithKeyCase(tCase.KeyCase))
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		fromStruct, err := localize.NewMapFromStruct("keyCase", account{UserID: 7}, localize.WithKeyCase(tCase.KeyCase))
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+
+		output := string(m.JS())
+		keys := fromStruct.Keys()
+		for _, key := range tCase.Expected {
+			if str := "\"" + key + "\":"; !strings.Contains(output, str) {
+				t.Run(name, func(t *testing.T) {
+					t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+				})
+			}
+			if !fromStruct.Has(key) {
+				t.Run(name, func(t *testing.T) {
+					t.Errorf("Expected keys to contain: %q,\ngot: %q\n", key, keys)
+				})
+			}
+		}
+	}
+
+	m, err := localize.NewMap("keyCase", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetKeyCase(localize.KeyCase(-1)); localize.ErrInvalidKeyCase != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidKeyCase, err)
+	}
+}
+
+// TestUnexportedFields ensures that only exported struct fields
+// are localized.
+func TestUnexportedFields(t *testing.T) {
+	token := "abc123"
+	data := localize.Data{
+		"session": session{
+			ID:     "s1",
+			secret: "hunter2",
+			Active: true,
+			token:  &token,
+		},
+	}
+	m, err := localize.NewMap("unexportedCase", data)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+	output := string(m.JS())
+
+	for _, str := range []string{"secret", "hunter2", "token", "abc123"} {
+		if strings.Contains(output, str) {
+			t.Errorf("Expected output not to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+	expected := "unexportedCase = {\n\"session\":{\n\"ID\":\"s1\",\n\"Active\":true\n}\n};"
+	if expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// optionalFields holds a zero value of each kind, both with and
+// without the "omitempty" option.
+type optionalFields struct {
+	String      string            `json:"string,omitempty"`
+	KeptString  string            `json:"keptString"`
+	Int         int               `json:"int,omitempty"`
+	KeptInt     int               `json:"keptInt"`
+	Float       float64           `json:"float,omitempty"`
+	KeptFloat   float64           `json:"keptFloat"`
+	Bool        bool              `json:"bool,omitempty"`
+	KeptBool    bool              `json:"keptBool"`
+	Pointer     *int              `json:"pointer,omitempty"`
+	KeptPointer *int              `json:"keptPointer"`
+	Slice       []int             `json:"slice,omitempty"`
+	KeptSlice   []int             `json:"keptSlice"`
+	Map         map[string]string `json:"map,omitempty"`
+	KeptMap     map[string]string `json:"keptMap"`
+	Full        string            `json:"full,omitempty"`
+}
+
+// TestOmitEmpty ensures that fields with the "omitempty" option
+// are left out when they're empty, and kept otherwise.
+func TestOmitEmpty(t *testing.T) {
+	data := localize.Data{
+		"fields": optionalFields{
+			Slice:     []int{},
+			KeptSlice: []int{},
+			Map:       map[string]string{},
+			KeptMap:   map[string]string{},
+			Full:      "full",
+		},
+	}
+	m, err := localize.NewMap("omitCase", data)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+	output := string(m.JS())
+
+	var decoded map[string]map[string]interface{}
+	raw := strings.TrimSuffix(strings.TrimPrefix(output, "omitCase = "), ";")
+	if err := json.Unmarshal([]byte(raw), &decoded); nil != err {
+		t.Fatalf("Failed to decode strict output: %q,\nerr: %v\n", raw, err)
+	}
+	for _, key := range []string{"string", "int", "float", "bool", "pointer", "slice", "map"} {
+		if _, ok := decoded["fields"][key]; ok {
+			t.Errorf("Expected empty field, %v, to be omitted,\ngot: %q\n", key, output)
+		}
+	}
+	for _, key := range []string{"keptString", "keptInt", "keptFloat", "keptBool", "keptPointer", "keptSlice", "keptMap", "full"} {
+		if _, ok := decoded["fields"][key]; !ok {
+			t.Errorf("Expected field, %v, to be kept,\ngot: %q\n", key, output)
+		}
+	}
+}
+
+// timestamps is embedded, and its fields are promoted.
+type timestamps struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// audit is embedded through an unexported type and a pointer.
+type audit struct {
+	Author string
+}
+
+// identity collides with the fields of the embedding struct.
+type identity struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Kind string
+}
+
+// origin is embedded alongside identity, and its untagged Kind
+// field is ambiguous.
+type origin struct {
+	Kind   string
+	Source string `json:"source"`
+}
+
+// post embeds structs in each of the ways encoding/json handles.
+type post struct {
+	timestamps
+	*audit
+	identity
+	origin
+	Meta  timestamps `json:"meta"`
+	Title string     `json:"title"`
+	Name  string     `json:"name"`
+}
+
+// TestEmbeddedStructs ensures that the fields of embedded structs
+// are flattened into the parent, as encoding/json does.
+func TestEmbeddedStructs(t *testing.T) {
+	data := localize.Data{
+		"post": post{
+			timestamps: timestamps{Created: 1, Updated: 2},
+			audit:      &audit{Author: "Forest"},
+			identity:   identity{ID: 7, Name: "shadowed", Kind: "ambiguous"},
+			origin:     origin{Kind: "ambiguous", Source: "rss"},
+			Meta:       timestamps{Created: 3, Updated: 4},
+			Title:      "Hello world!",
+			Name:       "post",
+		},
+		"nilEmbedded": post{},
+	}
+	m, err := localize.NewMap("embeddedCase", data)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+	output := string(m.JS())
+
+	if expected := "\"post\":{\n\"created\":1,\n\"updated\":2,\n\"Author\":\"Forest\",\n\"id\":7,"; !strings.Contains(output, expected) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", expected, output)
+	}
+	var decoded interface{}
+	raw := strings.TrimSuffix(strings.TrimPrefix(output, "embeddedCase = "), ";")
+	if err := json.Unmarshal([]byte(raw), &decoded); nil != err {
+		t.Fatalf("Failed to decode strict output: %q,\nerr: %v\n", raw, err)
+	}
+	if expected := normalize(t, data); !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected: %v,\ngot: %v\n", expected, decoded)
+	}
+}
+
+// loopNode embeds a pointer to its own type.
+type loopNode struct {
+	*loopNode
+	V int
+}
+
+// TestSelfEmbeddedStruct ensures that a struct embedding itself
+// through a pointer is flattened once, rather than forever.
+func TestSelfEmbeddedStruct(t *testing.T) {
+	n := &loopNode{V: 1}
+	n.loopNode = n
+	m, err := localize.NewMap("selfEmbeddedCase", localize.Data{"node": n})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	expected := "selfEmbeddedCase = {\n\"node\":{\n\"V\":1,\n},\n};"
+	if output, err := m.JSWithError(); nil != err || expected != string(output) {
+		t.Errorf("Expected: %q,\ngot: %q, err: %v\n", expected, output, err)
+	}
+	if output := string(m.JS()); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+	if err := m.Validate(); nil != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+	}
+	if _, err := m.TypeScriptDecl(); nil != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+	}
+}
+
+// account holds fields with the "string" struct tag option.
+type account struct {
+	ID      int64    `json:"id,string"`
+	Balance float64  `json:"balance,string"`
+	Active  bool     `json:"active,string"`
+	Label   string   `json:"label,string"`
+	Limit   *uint    `json:"limit,string"`
+	Tags    []string `json:"tags,string"`
+	Plain   int64    `json:"plain"`
+}
+
+// TestStringTagOption ensures that fields with the "string"
+// option hold their value as a string, as encoding/json does.
+func TestStringTagOption(t *testing.T) {
+	limit := uint(500)
+	data := localize.Data{
+		"account": account{
+			ID:      1<<53 + 1,
+			Balance: 12.5,
+			Active:  true,
+			Label:   "primary",
+			Limit:   &limit,
+			Tags:    []string{"a"},
+			Plain:   1<<53 + 1,
+		},
+	}
+	m, err := localize.NewMap("stringTagCase", data)
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+	output := string(m.JS())
+
+	expected := []string{
+		`"id":"9007199254740993",`,
+		`"balance":"12.5",`,
+		`"active":"true",`,
+		`"label":"\"primary\"",`,
+		`"limit":"500",`,
+		`"tags":["a"],`,
+		`"plain":9007199254740993`,
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+
+	var decoded interface{}
+	raw := strings.TrimSuffix(strings.TrimPrefix(output, "stringTagCase = "), ";")
+	if err := json.Unmarshal([]byte(raw), &decoded); nil != err {
+		t.Fatalf("Failed to decode strict output: %q,\nerr: %v\n", raw, err)
+	}
+	if expected := normalize(t, data); !reflect.DeepEqual(expected, decoded) {
+		t.Errorf("Expected: %v,\ngot: %v\n", expected, decoded)
+	}
+}
+
+// TestMapKeys ensures that integer and boolean map keys are
+// converted to strings, and that other kinds of keys are
+// reported.
+func TestMapKeys(t *testing.T) {
+	keyCases := map[string]struct {
+		Input    interface{}
+		Expected interface{}
+	}{
+		"intKeys": {
+			map[int]string{1: "one", -2: "minus two"},
+			map[string]interface{}{"1": "one", "-2": "minus two"},
+		},
+		"uintKeys": {
+			map[uint8]int{7: 49},
+			map[string]interface{}{"7": float64(49)},
+		},
+		"boolKeys": {
+			map[bool]int{true: 1, false: 0},
+			map[string]interface{}{"true": float64(1), "false": float64(0)},
+		},
+		"interfaceKeys": {
+			map[interface{}]interface{}{"name": "yaml", 2: "two", uint8(3): true},
+			map[string]interface{}{"name": "yaml", "2": "two", "3": true},
+		},
+	}
+	for name, tCase := range keyCases {
+		m, err := localize.NewMap(name, localize.Data{
+			"keyed": tCase.Input,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		output, err := m.JSON()
+		if nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Failed to get JSON,\nerr: %v\n", err)
+			})
+			continue
+		}
+
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(output, &decoded); nil != err {
+			t.Fatalf("Failed to decode JSON: %q,\nerr: %v\n", output, err)
+		}
+		if !reflect.DeepEqual(tCase.Expected, decoded["keyed"]) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %v,\ngot: %v\n", tCase.Expected, decoded["keyed"])
+			})
+		}
+	}
+
+	type point struct{ X, Y int }
+	m, err := localize.NewMap("structKeys", localize.Data{
+		"keyed": map[point]string{point{1, 2}: "a"},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if _, err := m.JSWithError(); nil == err || !strings.Contains(err.Error(), "struct") {
+		t.Errorf("Expected an error for the struct key,\ngot: %v\n", err)
+	}
+
+	// Interface keys are reported by their concrete kind.
+	m, err = localize.NewMap("interfaceKeys", localize.Data{
+		"keyed": map[interface{}]string{"ok": "a", 1.5: "b"},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if _, err := m.JSWithError(); !errors.Is(err, localize.ErrUnsupportedKind) || !strings.Contains(err.Error(), "float64") {
+		t.Errorf("Expected an error for the float64 key,\ngot: %v\n", err)
+	}
+
+	// Keys that convert to the same string are reported.
+	m, err = localize.NewMap("collidingKeys", localize.Data{
+		"keyed": map[interface{}]int{1: 1, "1": 2, "ok": 3},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output, err := m.JSWithError()
+	if !errors.Is(err, localize.ErrDuplicateKey) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrDuplicateKey, err)
+	}
+	if expected := "\"keyed\":{\n\"ok\":3,\n},"; !strings.Contains(string(output), expected) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", expected, output)
+	}
+
+	// Integer interface keys are sorted by their value.
+	m, err = localize.NewMap("sortedKeys", localize.Data{
+		"keyed": map[interface{}]int{10: 10, uint8(2): 2, -1: -1, 1: 1},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output, err = m.JSWithError()
+	if nil != err {
+		t.Fatalf("Failed to get JS,\nerr: %v\n", err)
+	}
+	if expected := "\"keyed\":{\n\"-1\":-1,\n\"1\":1,\n\"2\":2,\n\"10\":10,\n},"; !strings.Contains(string(output), expected) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// TestNumericMapKeys ensures that integer map keys are sorted
+// by their value, rather than by their string representation.
+func TestNumericMapKeys(t *testing.T) {
+	m, err := localize.NewMap("numericKeys", localize.Data{
+		"ids":    map[int]string{10: "ten", 2: "two", 1: "one"},
+		"counts": map[uint16]bool{10: true, 2: false, 1: true},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"ids\":{\n\"1\":\"one\",\n\"2\":\"two\",\n\"10\":\"ten\",\n}",
+		"\"counts\":{\n\"1\":true,\n\"2\":false,\n\"10\":true,\n}",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// TestVerify ensures that data which round-trips cleanly passes
+// verification, and that mismatched output is reported by key.
+func TestVerify(t *testing.T) {
+	for name, data := range jsonCases {
+		m, err := localize.NewMap(name, data)
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.Verify(); nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected no error,\ngot: %v\n", err)
+			})
+		}
+	}
+
+	m, err := localize.NewMap("verifyCase", localize.Data{
+		"count": 5,
+		"order": map[string]interface{}{
+			"total": money(1050),
+		},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	// A handler which renders money as a formatted string changes
+	// the value that comes back out.
+	m.RegisterHandler(reflect.TypeOf(money(0)), func(v reflect.Value) (string, error) {
+		cents := v.Int()
+		return fmt.Sprintf("%q", fmt.Sprintf("$%d.%02d", cents/100, cents%100)), nil
+	})
+	err = m.Verify()
+	if !errors.Is(err, localize.ErrVerification) {
+		t.Fatalf("Expected err: %v,\ngot: %v\n", localize.ErrVerification, err)
+	}
+	if str := `"order.total"`; !strings.Contains(err.Error(), str) {
+		t.Errorf("Expected err to contain: %q,\ngot: %q\n", str, err.Error())
+	}
+
+	m.RegisterHandler(reflect.TypeOf(money(0)), nil)
+	if err := m.Verify(); nil != err {
+		t.Errorf("Expected no error,\ngot: %v\n", err)
+	}
+
+	// Values that the library deliberately writes differently from
+	// encoding/json are verified by the library's rules.
+	documentedCases := map[string]interface{}{
+		"nilSlice":   []string(nil),
+		"nilMap":     map[string]int(nil),
+		"complex64":  complex64(complex(1.5, -2)),
+		"complex128": []complex128{complex(0, 1)},
+		"nested":     struct{ Tags []int }{},
+		"account":    account{ID: 1<<53 + 1, Label: "primary"},
+	}
+	for name, value := range documentedCases {
+		m, err := localize.NewMap("verifyDocumentedCase", localize.Data{name: value})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.Verify(); nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected no error,\ngot: %v\n", err)
+			})
+		}
+	}
+}
+
+// TestValidate ensures that data which can be localized passes
+// validation, and that the first problem is reported otherwise.
+func TestValidate(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+	cyclic := &node{}
+	cyclic.Next = cyclic
+
+	validateCases := map[string]struct {
+		Data     localize.Data
+		Expected error
+	}{
+		"clean": {localize.Data{
+			"motd":  "Hello world!",
+			"nonce": map[string]string{"login": "abc"},
+		}, nil},
+		"channel": {localize.Data{"events": make(chan int)}, localize.ErrUnsupportedKind},
+		"cyclic":  {localize.Data{"list": cyclic}, localize.ErrCyclicData},
+		"key":     {localize.Data{"": 1}, localize.ErrInvalidKey},
+	}
+	for name, tCase := range validateCases {
+		m, err := localize.NewMap("validateCase", tCase.Data)
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		err = m.Validate()
+		if (nil == tCase.Expected && nil != err) || !errors.Is(err, tCase.Expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", tCase.Expected, err)
+			})
+		}
+	}
+}
+
+// TestDataEqual ensures that data is compared by content, rather
+// than by formatting, key order, or numeric type.
+func TestDataEqual(t *testing.T) {
+	m, err := localize.NewMap("equalCase", localize.Data{
+		"motd":  "Hello world!",
+		"count": 3,
+		"nonce": map[string]string{"login": "abc", "logout": "def"},
+		"ui":    localize.Data{"theme": localize.Data{"color": "blue"}},
+	}, localize.WithIndent("", "\t"))
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	equalCases := map[string]struct {
+		Other    localize.Data
+		Expected bool
+	}{
+		"equal": {localize.Data{
+			"ui":    map[string]interface{}{"theme": map[string]string{"color": "blue"}},
+			"nonce": localize.Data{"logout": "def", "login": "abc"},
+			"count": float64(3),
+			"motd":  "Hello world!",
+		}, true},
+		"nestedValue": {localize.Data{
+			"motd":  "Hello world!",
+			"count": 3,
+			"nonce": map[string]string{"login": "abc", "logout": "def"},
+			"ui":    localize.Data{"theme": localize.Data{"color": "red"}},
+		}, false},
+		"missingKey": {localize.Data{
+			"motd":  "Hello world!",
+			"count": 3,
+			"nonce": map[string]string{"login": "abc"},
+			"ui":    localize.Data{"theme": localize.Data{"color": "blue"}},
+		}, false},
+		"extraKey": {localize.Data{
+			"motd":  "Hello world!",
+			"count": 3,
+			"nonce": map[string]string{"login": "abc", "logout": "def"},
+			"ui":    localize.Data{"theme": localize.Data{"color": "blue"}},
+			"extra": true,
+		}, false},
+		"nil": {nil, false},
+	}
+	for name, tCase := range equalCases {
+		if equal := m.DataEqual(tCase.Other); tCase.Expected != equal {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %v,\ngot: %v\n", tCase.Expected, equal)
+			})
+		}
+	}
+}
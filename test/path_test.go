@@ -0,0 +1,150 @@
+/**
+ * path_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/foresthoffman/localize"
+)
+
+// TestSetPath ensures that elements are inserted at dotted paths,
+// creating the intermediate maps, and that paths through other
+// elements are reported.
+func TestSetPath(t *testing.T) {
+	m, err := localize.NewMap("pathCase", localize.Data{
+		"ui":      localize.Data{"lang": "en"},
+		"version": 3,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetPath("ui.theme.color", "blue"); nil != err {
+		t.Fatalf("Failed to set path,\nerr: %v\n", err)
+	}
+	if err := m.SetPath("motd", "Hello world!"); nil != err {
+		t.Fatalf("Failed to set path,\nerr: %v\n", err)
+	}
+
+	expected := localize.Data{
+		"ui": localize.Data{
+			"lang":  "en",
+			"theme": localize.Data{"color": "blue"},
+		},
+		"version": 3,
+		"motd":    "Hello world!",
+	}
+	if data := m.GetData(); !reflect.DeepEqual(expected, data) {
+		t.Errorf("Expected: %v,\ngot: %v\n", expected, data)
+	}
+
+	conflictCases := map[string]struct {
+		Path     string
+		Sentinel error
+		Key      string
+	}{
+		"leaf":      {"version.major", localize.ErrPathConflict, `"version"`},
+		"nested":    {"ui.lang.code", localize.ErrPathConflict, `"ui.lang"`},
+		"emptyPart": {"ui..color", localize.ErrInvalidKey, `""`},
+	}
+	for name, tCase := range conflictCases {
+		err := m.SetPath(tCase.Path, "red")
+		if !errors.Is(err, tCase.Sentinel) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", tCase.Sentinel, err)
+			})
+			continue
+		}
+		if !strings.Contains(err.Error(), "key "+tCase.Key) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected error to name the key, %v,\ngot: %v\n", tCase.Key, err)
+			})
+		}
+	}
+	if data := m.GetData(); !reflect.DeepEqual(expected, data) {
+		t.Errorf("Expected: %v,\ngot: %v\n", expected, data)
+	}
+}
+
+// TestGetPath ensures that elements are retrieved from dotted
+// paths, and that paths which don't resolve are reported.
+func TestGetPath(t *testing.T) {
+	m, err := localize.NewMap("pathCase", localize.Data{
+		"ui": localize.Data{
+			"theme": localize.Data{"color": "blue"},
+			"lang":  "en",
+		},
+		"none": nil,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	pathCases := map[string]struct {
+		Path     string
+		Expected interface{}
+		Ok       bool
+	}{
+		"leaf":      {"ui.theme.color", "blue", true},
+		"topLevel":  {"ui.lang", "en", true},
+		"nil":       {"none", nil, true},
+		"subtree":   {"ui.theme", localize.Data{"color": "blue"}, true},
+		"absent":    {"ui.theme.size", nil, false},
+		"absentMid": {"ux.theme.color", nil, false},
+		"nonMap":    {"ui.lang.code", nil, false},
+	}
+	for name, tCase := range pathCases {
+		data, ok := m.GetPath(tCase.Path)
+		if tCase.Ok != ok || !reflect.DeepEqual(tCase.Expected, data) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %v, %v,\ngot: %v, %v\n", tCase.Expected, tCase.Ok, data, ok)
+			})
+		}
+	}
+}
+
+// TestDeletePath ensures that elements are removed from dotted
+// paths, keeping their parents, and that paths which don't
+// resolve are reported.
+func TestDeletePath(t *testing.T) {
+	m, err := localize.NewMap("pathCase", localize.Data{
+		"ui": localize.Data{
+			"theme": localize.Data{"color": "blue"},
+			"lang":  "en",
+		},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.DeletePath("ui.theme.color"); nil != err {
+		t.Fatalf("Failed to delete path,\nerr: %v\n", err)
+	}
+
+	expected := localize.Data{
+		"ui": localize.Data{
+			"theme": localize.Data{},
+			"lang":  "en",
+		},
+	}
+	if data := m.GetData(); !reflect.DeepEqual(expected, data) {
+		t.Errorf("Expected: %v,\ngot: %v\n", expected, data)
+	}
+
+	for _, path := range []string{"ui.theme.color", "ux.theme", "ui.lang.code", ""} {
+		err := m.DeletePath(path)
+		if !errors.Is(err, localize.ErrPathNotFound) {
+			t.Run(path, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrPathNotFound, err)
+			})
+		}
+	}
+}
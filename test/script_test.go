@@ -0,0 +1,119 @@
+/**
+ * script_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/foresthoffman/localize"
+)
+
+// TestScriptTag ensures that the localized data is wrapped in a
+// script element carrying the escaped nonce.
+func TestScriptTag(t *testing.T) {
+	m, err := localize.NewMap("_localData", localize.Data{
+		"motd": "</script>",
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	nonceCases := map[string]template.HTML{
+		"LaKJIIjIOUhjbKHdBJHGkhg": `<script type="text/javascript" nonce="LaKJIIjIOUhjbKHdBJHGkhg">_localData = {
+"motd":"\u003c/script\u003e",
+};</script>`,
+		`"><script>`: `<script type="text/javascript" nonce="&#34;&gt;&lt;script&gt;">_localData = {
+"motd":"\u003c/script\u003e",
+};</script>`,
+	}
+	for nonce, expected := range nonceCases {
+		if output := m.ScriptTag(nonce); expected != output {
+			t.Run(nonce, func(t *testing.T) {
+				t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+			})
+		}
+	}
+}
+
+// TestScript ensures that the localized data is wrapped in a
+// script element of the configured type.
+func TestScript(t *testing.T) {
+	typeCases := map[string]struct {
+		Type     localize.ScriptType
+		Expected template.HTML
+	}{
+		"default": {localize.ScriptTypeJavaScript, "<script type=\"text/javascript\">_localData = {\n\"int\":1954,\n};</script>"},
+		"module":  {localize.ScriptTypeModule, "<script type=\"module\">_localData = {\n\"int\":1954,\n};</script>"},
+	}
+	for name, tCase := range typeCases {
+		m, err := localize.NewMap("_localData", localize.Data{
+			"int": 1954,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetScriptType(tCase.Type); nil != err {
+			t.Fatalf("Failed to set script type,\nerr: %v\n", err)
+		}
+
+		if output := m.Script(); tCase.Expected != output {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+
+	m, err := localize.NewMap("_localData", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetScriptType(localize.ScriptType(-1)); localize.ErrInvalidScriptType != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidScriptType, err)
+	}
+}
+
+// TestJSONScript ensures that the localized data is embedded as
+// a JSON data block, which can't be closed by its contents.
+func TestJSONScript(t *testing.T) {
+	m, err := localize.NewMap("_localData", localize.Data{
+		"motd": "</script><script>alert(1)</script>",
+		"amp":  "Tom & Jerry",
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	expected := template.HTML(`<script type="application/json" id="local-data">{
+"amp":"Tom \u0026 Jerry",
+"motd":"\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e"
+}</script>`)
+	output := m.JSONScript("local-data")
+	if expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+
+	// The contents are still the same JSON, once unescaped.
+	start := strings.Index(string(output), ">") + 1
+	end := strings.LastIndex(string(output), "</script>")
+	var data map[string]string
+	if err := json.Unmarshal([]byte(output[start:end]), &data); nil != err {
+		t.Fatalf("Failed to decode JSON: %q,\nerr: %v\n", output[start:end], err)
+	}
+	if "Tom & Jerry" != data["amp"] {
+		t.Errorf("Expected: %q,\ngot: %q\n", "Tom & Jerry", data["amp"])
+	}
+
+	// The id is escaped as an attribute value.
+	if output := m.JSONScript(`"><img>`); !strings.HasPrefix(string(output), `<script type="application/json" id="&#34;&gt;&lt;img&gt;">`) {
+		t.Errorf("Expected the id to be escaped,\ngot: %q\n", output)
+	}
+}
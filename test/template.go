@@ -81,12 +81,11 @@ func ListenAndServeWithClose(ctx context.Context, port int) error {
             <script type="text/javascript">
                 window.onload = function() {
 
-                    // Access the first element of the motd
-                    // property of the _localData variable to
-                    // get the message of the day, and then
-                    // insert it into the motd span of the
-                    // header tag on the page.
-                    document.querySelector(".page .motd").innerText = _localData.motd[0];
+                    // Access the motd property of the
+                    // _localData variable to get the message
+                    // of the day, and then insert it into the
+                    // motd span of the header tag on the page.
+                    document.querySelector(".page .motd").innerText = _localData.motd;
                 };
             </script>
         </body>
@@ -35,38 +35,14 @@ func TestTemplate(t *testing.T) {
 	if nil != err {
 		t.Fatalf("Failed to read from response body,\nerr: %v\n", err)
 	}
-	// The order of elements in a map is not guaranteed,
-	// therefore, both potential orders have to be checked.
-	expected := []string{
-		`_localData = {
-"motd": [
-"Hello world, welcome to a new day!",
-],
-"nonce": {
+	expected := `_localData = {
+"motd":"Hello world, welcome to a new day!",
+"nonce":{
 "login":"LaKJIIjIOUhjbKHdBJHGkhg",
-
-},
-
-};`,
-		`_localData = {
-"nonce": {
-"login":"LaKJIIjIOUhjbKHdBJHGkhg",
-
 },
-"motd": [
-"Hello world, welcome to a new day!",
-],
-
-};`,
-	}
-	matched := false
-	for _, str := range expected {
-		if index := strings.Index(string(contents), str); -1 != index {
-			matched = true
-		}
-	}
-	if !matched {
-		t.Fatalf("Failed to find localized data,\nexpected one of: %v,\nbody: %v\n", expected, string(contents))
+};`
+	if index := strings.Index(string(contents), expected); -1 == index {
+		t.Fatalf("Failed to find localized data,\nexpected: %v,\nbody: %v\n", expected, string(contents))
 	}
 	cancel()
 }
@@ -0,0 +1,1311 @@
+/**
+ * types_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/foresthoffman/localize"
+)
+
+// TestSizedInts ensures that every sized signed integer kind is
+// localized, rather than only the plain "int" kind.
+func TestSizedInts(t *testing.T) {
+	m, err := localize.NewMap("sizedInts", localize.Data{
+		"int8":  int8(-8),
+		"int16": int16(1600),
+		"int32": int32(-320000),
+		"int64": int64(6400000000),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"int8\":-8,",
+		"\"int16\":1600,",
+		"\"int32\":-320000,",
+		"\"int64\":6400000000,",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// TestFloat32 ensures that float32 values are localized at
+// their own precision, without widening artifacts.
+func TestFloat32(t *testing.T) {
+	cases := map[string]struct {
+		Input    float32
+		Expected string
+	}{
+		"1.5": {1.5, "\"ratio\":1.5,"},
+		"0.1": {0.1, "\"ratio\":0.1,"},
+	}
+	for name, tCase := range cases {
+		m, err := localize.NewMap("float32Case", localize.Data{
+			"ratio": tCase.Input,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if output := string(m.JS()); !strings.Contains(output, tCase.Expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to contain: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+}
+
+// TestComplex ensures that complex numbers of both widths are
+// localized as [real, imag] arrays.
+func TestComplex(t *testing.T) {
+	cases := map[string]struct {
+		Input    interface{}
+		Expected string
+	}{
+		"complex64":  {complex64(complex(1, 2)), "\"z\":[1,2]"},
+		"complex128": {complex(1, 2), "\"z\":[1,2]"},
+		"fractional": {complex(0.5, -0.25), "\"z\":[0.5,-0.25]"},
+	}
+	for name, tCase := range cases {
+		m, err := localize.NewMap("complexCase", localize.Data{
+			"z": tCase.Input,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		m.SetStrict(true)
+		output, err := m.JSWithError()
+		if nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+			})
+			continue
+		}
+		if !strings.Contains(string(output), tCase.Expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to contain: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+}
+
+// TestPointers ensures that pointers are dereferenced, and that
+// nil pointers are localized as null.
+func TestPointers(t *testing.T) {
+	name := "Forest"
+	var count *int
+	m, err := localize.NewMap("pointerCase", localize.Data{
+		"name":  &name,
+		"count": count,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"name\":\"Forest\",",
+		"\"count\":null,",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// item is a struct that's referenced through pointers.
+type item struct {
+	Name string `json:"name"`
+}
+
+// TestPointerChains ensures that chains of pointers and
+// interfaces are fully dereferenced, with nil at any hop
+// localized as null.
+func TestPointerChains(t *testing.T) {
+	count := 1954
+	countPtr := &count
+	ints := []int{1, 2}
+	items := []*item{{"first"}, nil}
+	var nilPtr *int
+	m, err := localize.NewMap("pointerChainCase", localize.Data{
+		"count":   &countPtr,
+		"ints":    &ints,
+		"item":    interface{}(&item{"only"}),
+		"items":   &items,
+		"nilHop":  &nilPtr,
+		"nilItem": interface{}((*item)(nil)),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetStrict(true)
+	output, err := m.JSWithError()
+	if nil != err {
+		t.Fatalf("Expected err: %v,\ngot: %v\n", nil, err)
+	}
+
+	expected := []string{
+		"\"count\":1954,",
+		"\"ints\":[1,2],",
+		"\"item\":{\n\"name\":\"only\"\n},",
+		"\"items\":[{\n\"name\":\"first\"\n},null],",
+		"\"nilHop\":null,",
+		"\"nilItem\":null\n",
+	}
+	for _, str := range expected {
+		if !strings.Contains(string(output), str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// node is a linked struct, which can refer back to itself.
+type node struct {
+	Name string `json:"name"`
+	Next *node  `json:"next"`
+}
+
+// TestCyclicData ensures that cyclic data is reported, rather
+// than recursing forever.
+func TestCyclicData(t *testing.T) {
+	self := &node{Name: "self"}
+	self.Next = self
+	loop := map[string]interface{}{"name": "loop"}
+	loop["self"] = loop
+	list := []interface{}{"list", nil}
+	list[1] = list
+
+	cyclicCases := map[string]struct {
+		Input interface{}
+		Key   string
+	}{
+		"pointer": {self, "value.next"},
+		"map":     {loop, "value.self"},
+		"slice":   {list, "value[1]"},
+	}
+	for name, tCase := range cyclicCases {
+		m, err := localize.NewMap("cyclicCase", localize.Data{
+			"value": tCase.Input,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if _, err := m.JSWithError(); !errors.Is(err, localize.ErrCyclicData) || !strings.Contains(err.Error(), tCase.Key) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v, at key, %v,\ngot: %v\n", localize.ErrCyclicData, tCase.Key, err)
+			})
+		}
+	}
+
+	// Data that's shared without a cycle isn't affected.
+	shared := &node{Name: "shared"}
+	m, err := localize.NewMap("sharedCase", localize.Data{
+		"first":  shared,
+		"second": []*node{shared, shared},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if _, err := m.JSWithError(); nil != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+	}
+}
+
+// TestMaxDepth ensures that data nested deeper than the maximum
+// depth is reported.
+func TestMaxDepth(t *testing.T) {
+	m, err := localize.NewMapFromJSON("depthCase", []byte(`{"a":{"b":{"c":[1,{"d":true}]}},"flat":1}`))
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	depthCases := map[int]struct {
+		Err      error
+		Expected string
+	}{
+		0: {nil, "\"d\":true"},
+		5: {nil, "\"d\":true"},
+		4: {localize.ErrMaxDepth, "\"c\":[1,null,],"},
+		2: {localize.ErrMaxDepth, "\"a\":{\n\"b\":null,\n},"},
+		1: {localize.ErrMaxDepth, "\"a\":null,\n\"flat\":1,"},
+	}
+	for depth, tCase := range depthCases {
+		if err := m.SetMaxDepth(depth); nil != err {
+			t.Fatalf("Failed to set maximum depth,\nerr: %v\n", err)
+		}
+		output, err := m.JSWithError()
+		if !errors.Is(err, tCase.Err) {
+			t.Run(fmt.Sprint(depth), func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", tCase.Err, err)
+			})
+		}
+		if !strings.Contains(string(output), tCase.Expected) {
+			t.Run(fmt.Sprint(depth), func(t *testing.T) {
+				t.Errorf("Expected output to contain: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+
+	if err := m.SetMaxDepth(-1); localize.ErrInvalidMaxDepth != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidMaxDepth, err)
+	}
+}
+
+// TestNilInterface ensures that nil interface values are
+// localized as null, rather than causing a panic.
+func TestNilInterface(t *testing.T) {
+	m, err := localize.NewMap("nilCase", localize.Data{
+		"maybe": nil,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	expected := "nilCase = {\n\"maybe\":null,\n};"
+	if output := string(m.JS()); expected != output {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// TestInterfaceValues ensures that values held by interfaces are
+// bracketed according to their unwrapped kind.
+func TestInterfaceValues(t *testing.T) {
+	m, err := localize.NewMap("interfaceCase", localize.Data{
+		"values": map[string]interface{}{
+			"string": "plain",
+			"map":    map[string]int{"a": 1},
+			"slice":  []int{1, 2},
+			"array":  [2]string{"x", "y"},
+		},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"string\":\"plain\",",
+		"\"map\":{\n\"a\":1,\n},",
+		"\"slice\":[1,2,],",
+		"\"array\":[\"x\",\"y\",],",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// TestInterfaceScalars ensures that booleans and integers held by
+// interfaces are localized as bare values, without being wrapped.
+func TestInterfaceScalars(t *testing.T) {
+	var flag interface{} = true
+	var count interface{} = 42
+	m, err := localize.NewMap("scalarCase", localize.Data{
+		"flag":   flag,
+		"count":  count,
+		"nested": map[string]interface{}{"flag": flag, "count": &count},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\n\"flag\":true,",
+		"\n\"count\":42,",
+		"\"nested\":{\n\"count\":42,\n\"flag\":true,\n},",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// TestMixedSlices ensures that interface slices holding elements
+// of different types are localized as arrays, without stray line
+// breaks between the elements.
+func TestMixedSlices(t *testing.T) {
+	mixedCases := map[string]struct {
+		Strict   bool
+		Expected string
+	}{
+		"loose":  {false, "\"mixed\":[1,\"two\",true,null,[3,],],"},
+		"strict": {true, "\"mixed\":[1,\"two\",true,null,[3]]"},
+	}
+	for name, tCase := range mixedCases {
+		m, err := localize.NewMap("mixedCase", localize.Data{
+			"mixed": []interface{}{1, "two", true, nil, []int{3}},
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		m.SetStrict(tCase.Strict)
+
+		if output := string(m.JS()); !strings.Contains(output, tCase.Expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to contain: %q,\ngot: %q\n", tCase.Expected, output)
+			})
+		}
+	}
+}
+
+// TestLeafValues ensures that non-enclosing values are written
+// the same way that encoding/json writes them.
+func TestLeafValues(t *testing.T) {
+	m, err := localize.NewMap("leafCase", localize.Data{
+		"uint":      uint(7),
+		"uint64":    uint64(18446744073709551615),
+		"uintptr":   uintptr(5),
+		"million":   1e6,
+		"separator": "line\u2028separator",
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"uint\":7,",
+		"\"uint64\":18446744073709551615,",
+		"\"uintptr\":5,",
+		"\"million\":1000000,",
+		"\"separator\":\"line\\u2028separator\",",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// TestArrays ensures that fixed-size arrays are localized like
+// slices.
+func TestArrays(t *testing.T) {
+	m, err := localize.NewMap("arrayCase", localize.Data{
+		"rgb":  [3]int{1, 2, 3},
+		"grid": [2][2]int{{1, 2}, {3, 4}},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"rgb\":[1,2,3,],",
+		"\"grid\":[[1,2,],[3,4,],],",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// TestMapsOfSlices ensures that map values which are slices or
+// arrays, such as query parameters, are wrapped in brackets.
+func TestMapsOfSlices(t *testing.T) {
+	m, err := localize.NewMap("paramCase", localize.Data{
+		"params": map[string][]string{"tags": {"a", "b"}},
+		"header": map[string][]string{"accept": {}},
+		"points": map[string][2]int{"origin": {0, 0}},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"params\":{\n\"tags\":[\"a\",\"b\",],\n},",
+		"\"header\":{\n\"accept\":[],\n},",
+		"\"points\":{\n\"origin\":[0,0,],\n},",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+
+	// Strict output decodes back into the same type.
+	jsonOutput, err := m.JSON()
+	if nil != err {
+		t.Fatalf("Failed to get JSON,\nerr: %v\n", err)
+	}
+	var decoded struct {
+		Params map[string][]string `json:"params"`
+	}
+	if err := json.Unmarshal(jsonOutput, &decoded); nil != err {
+		t.Fatalf("Failed to decode JSON: %q,\nerr: %v\n", jsonOutput, err)
+	}
+	if !reflect.DeepEqual([]string{"a", "b"}, decoded.Params["tags"]) {
+		t.Errorf("Expected: %v,\ngot: %v\n", []string{"a", "b"}, decoded.Params["tags"])
+	}
+}
+
+// TestMapsOfStructs ensures that map values which are structs,
+// or pointers to structs, are localized as nested objects.
+func TestMapsOfStructs(t *testing.T) {
+	m, err := localize.NewMap("structCase", localize.Data{
+		"items":    map[string]item{"first": {Name: "apple"}},
+		"pointers": map[string]*item{"first": {Name: "pear"}, "none": nil},
+		"lists":    map[string][]item{"fruit": {{Name: "plum"}}},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"items\":{\n\"first\":{\n\"name\":\"apple\",\n},\n},",
+		"\"pointers\":{\n\"first\":{\n\"name\":\"pear\",\n},\n\"none\":null,\n},",
+		"\"lists\":{\n\"fruit\":[{\n\"name\":\"plum\",\n},],\n},",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// TestSlicesOfStructs ensures that slices of structs are
+// localized as arrays of objects, with each object closed before
+// the comma that separates it from the next.
+func TestSlicesOfStructs(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	users := []user{{1, "Ada"}, {2, "Grace"}}
+	m, err := localize.NewMap("recordCase", localize.Data{
+		"users": users,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	expected := "\"users\":[{\n\"id\":1,\n\"name\":\"Ada\",\n},{\n\"id\":2,\n\"name\":\"Grace\",\n},],"
+	if output := string(m.JS()); !strings.Contains(output, expected) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", expected, output)
+	}
+
+	// Strict output decodes back into the same records.
+	jsonOutput, err := m.JSON()
+	if nil != err {
+		t.Fatalf("Failed to get JSON,\nerr: %v\n", err)
+	}
+	var decoded struct {
+		Users []user `json:"users"`
+	}
+	if err := json.Unmarshal(jsonOutput, &decoded); nil != err {
+		t.Fatalf("Failed to decode JSON: %q,\nerr: %v\n", jsonOutput, err)
+	}
+	if !reflect.DeepEqual(users, decoded.Users) {
+		t.Errorf("Expected: %v,\ngot: %v\n", users, decoded.Users)
+	}
+}
+
+// TestEmptySlices ensures that empty slices are localized as
+// bare empty arrays, without any interior whitespace.
+func TestEmptySlices(t *testing.T) {
+	m, err := localize.NewMap("emptySliceCase", localize.Data{
+		"ints":    []int{},
+		"strings": []string{},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	layouts := map[string]struct {
+		Strict   bool
+		Expected []string
+	}{
+		"default": {false, []string{
+			"\"ints\":[],",
+			"\"strings\":[],",
+		}},
+		"strict": {true, []string{
+			"\"ints\":[],",
+			"\"strings\":[]\n",
+		}},
+	}
+	for name, layout := range layouts {
+		m.SetStrict(layout.Strict)
+		output := string(m.JS())
+		for _, str := range layout.Expected {
+			if !strings.Contains(output, str) {
+				t.Run(name, func(t *testing.T) {
+					t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+				})
+			}
+		}
+	}
+}
+
+// TestEmptyObjects ensures that empty maps and structs are
+// localized as bare empty objects, without any stray newlines.
+func TestEmptyObjects(t *testing.T) {
+	m, err := localize.NewMap("emptyObjectCase", localize.Data{
+		"map":    map[string]string{},
+		"nested": map[string]interface{}{"inner": map[string]int{}},
+		"struct": struct{}{},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	layouts := map[string]struct {
+		Strict   bool
+		Expected []string
+	}{
+		"default": {false, []string{
+			"\"map\":{},\n",
+			"\"inner\":{},\n",
+			"\"struct\":{},\n",
+		}},
+		"strict": {true, []string{
+			"\"map\":{},",
+			"\"inner\":{}\n",
+			"\"struct\":{}\n",
+		}},
+	}
+	for name, layout := range layouts {
+		m.SetStrict(layout.Strict)
+		output := string(m.JS())
+		for _, str := range layout.Expected {
+			if !strings.Contains(output, str) {
+				t.Run(name, func(t *testing.T) {
+					t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+				})
+			}
+		}
+	}
+}
+
+// TestByteSlice ensures that byte slices are localized as base64
+// strings, rather than arrays of numbers.
+func TestByteSlice(t *testing.T) {
+	m, err := localize.NewMap("byteCase", localize.Data{
+		"thumbnail": []byte("hello"),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	expected := "\"thumbnail\":\"aGVsbG8=\","
+	if output := string(m.JS()); !strings.Contains(output, expected) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", expected, output)
+	}
+}
+
+// TestNetTypes ensures that net.IP and url.URL values are
+// localized as their canonical string forms.
+func TestNetTypes(t *testing.T) {
+	home, err := url.Parse("https://example.com/search?q=go&lang=en")
+	if nil != err {
+		t.Fatalf("Failed to parse URL,\nerr: %v\n", err)
+	}
+	m, err := localize.NewMap("netCase", localize.Data{
+		"ip":    net.ParseIP("192.0.2.1"),
+		"ipv6":  net.ParseIP("2001:db8::1"),
+		"home":  home,
+		"link":  *home,
+		"noURL": (*url.URL)(nil),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"ip\":\"192.0.2.1\",",
+		"\"ipv6\":\"2001:db8::1\",",
+		"\"home\":\"https://example.com/search?q=go\\u0026lang=en\",",
+		"\"link\":\"https://example.com/search?q=go\\u0026lang=en\",",
+		"\"noURL\":null,",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+}
+
+// semver is a version, rendered by its MarshalText method.
+type semver struct {
+	Major, Minor, Patch int
+}
+
+func (v semver) MarshalText() ([]byte, error) {
+	if 0 > v.Major {
+		return nil, errors.New("Negative major version")
+	}
+	return []byte(fmt.Sprintf("v%d.%d.%d", v.Major, v.Minor, v.Patch)), nil
+}
+
+// TestTextMarshaler ensures that values implementing
+// encoding.TextMarshaler are localized as their text, rather
+// than by reflecting over their fields.
+func TestTextMarshaler(t *testing.T) {
+	m, err := localize.NewMap("textCase", localize.Data{
+		"version":  semver{1, 2, 3},
+		"pointer":  &semver{0, 9, 0},
+		"versions": []semver{{1, 0, 0}, {2, 0, 0}},
+		"none":     (*semver)(nil),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"version\":\"v1.2.3\",",
+		"\"pointer\":\"v0.9.0\",",
+		"\"versions\":[\"v1.0.0\",\"v2.0.0\",],",
+		"\"none\":null,",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+
+	// MarshalText errors are reported, and the value is replaced.
+	if err := m.Add("version", semver{-1, 0, 0}); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+	js, err := m.JSWithError()
+	if nil == err {
+		t.Errorf("Expected an error,\ngot: %v\n", err)
+	}
+	if str := "\"version\":null,"; !strings.Contains(string(js), str) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, js)
+	}
+}
+
+// TestJSONNumbers ensures that json.Number values are localized
+// as the number literals they hold, rather than as strings.
+func TestJSONNumbers(t *testing.T) {
+	m, err := localize.NewMap("numberCase", localize.Data{
+		"answer":   json.Number("42"),
+		"pi":       json.Number("3.14"),
+		"exponent": json.Number("1e-7"),
+		"id":       json.Number("9007199254740993"),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		"\"answer\":42,",
+		"\"pi\":3.14,",
+		"\"exponent\":1e-7,",
+		"\"id\":9007199254740993,",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+
+	// Unsafe integers are subject to the integer policy.
+	if err := m.SetIntPolicy(localize.IntPolicyString); nil != err {
+		t.Fatalf("Failed to set integer policy,\nerr: %v\n", err)
+	}
+	if str := "\"id\":\"9007199254740993\","; !strings.Contains(string(m.JS()), str) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, m.JS())
+	}
+
+	// Invalid numbers are reported, and the value is replaced.
+	if err := m.Add("answer", json.Number("forty-two")); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+	js, err := m.JSWithError()
+	if nil == err {
+		t.Errorf("Expected an error,\ngot: %v\n", err)
+	}
+	if str := "\"answer\":null,"; !strings.Contains(string(js), str) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, js)
+	}
+}
+
+// point is rendered by its MarshalJSON method, which takes
+// precedence over its MarshalText method.
+type point struct {
+	X, Y  int
+	Label string
+}
+
+func (p point) MarshalJSON() ([]byte, error) {
+	if "" == p.Label {
+		return []byte(`{"x": 1,`), nil
+	}
+	return []byte(fmt.Sprintf(`{ "x": %d, "y": %d, "label": %q }`, p.X, p.Y, p.Label)), nil
+}
+
+func (p point) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", p.X, p.Y)), nil
+}
+
+// TestJSONMarshaler ensures that values implementing
+// json.Marshaler are localized as their compacted output, with
+// strings escaped as usual, and that invalid output is reported.
+func TestJSONMarshaler(t *testing.T) {
+	m, err := localize.NewMap("marshalerCase", localize.Data{
+		"point":   point{1, 2, "</script>"},
+		"pointer": &point{3, 4, "b"},
+		"none":    (*point)(nil),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	output := string(m.JS())
+
+	expected := []string{
+		`"point":{"x":1,"y":2,"label":"\u003c/script\u003e"},`,
+		`"pointer":{"x":3,"y":4,"label":"b"},`,
+		`"none":null,`,
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+
+	m.SetEscapeHTML(false)
+	if str := `"label":"</script>"`; !strings.Contains(string(m.JS()), str) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, m.JS())
+	}
+
+	// Invalid output is reported, and the value is replaced.
+	if err := m.Add("point", point{}); nil != err {
+		t.Fatalf("Failed to add element,\nerr: %v\n", err)
+	}
+	js, err := m.JSWithError()
+	if nil == err {
+		t.Errorf("Expected an error,\ngot: %v\n", err)
+	}
+	if str := "\"point\":null,"; !strings.Contains(string(js), str) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, js)
+	}
+}
+
+// TestTimeFormats ensures that time.Time values are localized
+// according to the map's time format.
+func TestTimeFormats(t *testing.T) {
+	moment := time.Date(2019, time.March, 14, 15, 9, 26, 535000000, time.UTC)
+	timeCases := map[string]struct {
+		Format   localize.TimeFormat
+		Layout   string
+		Input    interface{}
+		Expected string
+	}{
+		"rfc3339":          {localize.TimeFormatRFC3339, "", moment, `"2019-03-14T15:09:26.535Z"`},
+		"rfc3339Zero":      {localize.TimeFormatRFC3339, "", time.Time{}, `"0001-01-01T00:00:00Z"`},
+		"unixMilli":        {localize.TimeFormatUnixMilli, "", moment, `1552576166535`},
+		"unixZero":         {localize.TimeFormatUnixMilli, "", time.Time{}, `-62135596800000`},
+		"layout":           {localize.TimeFormatLayout, "2006-01-02", moment, `"2019-03-14"`},
+		"layoutZero":       {localize.TimeFormatLayout, "2006-01-02", time.Time{}, `"0001-01-01"`},
+		"unixMilliPointer": {localize.TimeFormatUnixMilli, "", &moment, `1552576166535`},
+		"layoutPointer":    {localize.TimeFormatLayout, "2006-01-02", &moment, `"2019-03-14"`},
+		"nilPointer":       {localize.TimeFormatUnixMilli, "", (*time.Time)(nil), `null`},
+	}
+	for name, tCase := range timeCases {
+		m, err := localize.NewMap("timeCase", localize.Data{
+			"moment": tCase.Input,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetTimeFormat(tCase.Format, tCase.Layout); nil != err {
+			t.Fatalf("Failed to set time format,\nerr: %v\n", err)
+		}
+
+		expected := "\"moment\":" + tCase.Expected + ","
+		if output := string(m.JS()); !strings.Contains(output, expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to contain: %q,\ngot: %q\n", expected, output)
+			})
+		}
+	}
+
+	m, err := localize.NewMap("timeCase", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetTimeFormat(localize.TimeFormatLayout, ""); localize.ErrInvalidTimeFormat != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidTimeFormat, err)
+	}
+}
+
+// TestDurationFormats ensures that time.Duration values are
+// localized according to the map's duration format.
+func TestDurationFormats(t *testing.T) {
+	durationCases := map[string]struct {
+		Format   localize.DurationFormat
+		Expected string
+	}{
+		"nano":   {localize.DurationFormatNano, `5400000000000`},
+		"string": {localize.DurationFormatString, `"1h30m0s"`},
+		"milli":  {localize.DurationFormatMilli, `5400000`},
+	}
+	for name, tCase := range durationCases {
+		m, err := localize.NewMap("durationCase", localize.Data{
+			"timeout": 90 * time.Minute,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetDurationFormat(tCase.Format); nil != err {
+			t.Fatalf("Failed to set duration format,\nerr: %v\n", err)
+		}
+
+		expected := "\"timeout\":" + tCase.Expected + ","
+		if output := string(m.JS()); !strings.Contains(output, expected) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected output to contain: %q,\ngot: %q\n", expected, output)
+			})
+		}
+	}
+}
+
+// color is an enum type with a human readable label.
+type color int
+
+const (
+	red color = iota
+	green
+)
+
+func (c color) String() string {
+	switch c {
+	case red:
+		return "red"
+	case green:
+		return "green"
+	}
+	return "unknown"
+}
+
+// TestStringers ensures that fmt.Stringer labels are only used
+// when enabled.
+func TestStringers(t *testing.T) {
+	stringerCases := map[string]struct {
+		Enabled  bool
+		Expected []string
+	}{
+		"disabled": {false, []string{
+			"\"color\":1,",
+			"\"timeout\":1000000000,",
+			"\"timeoutPointer\":1000000000,",
+			"\"numberPointer\":42,",
+		}},
+		"enabled": {true, []string{
+			"\"color\":\"green\",",
+			"\"colorPointer\":\"green\",",
+			"\"timeout\":1000000000,",
+			"\"timeoutPointer\":1000000000,",
+			"\"numberPointer\":42,",
+		}},
+	}
+	timeout := time.Second
+	number := json.Number("42")
+	color := green
+	for name, tCase := range stringerCases {
+		m, err := localize.NewMap("stringerCase", localize.Data{
+			"color":          green,
+			"colorPointer":   &color,
+			"timeout":        time.Second,
+			"timeoutPointer": &timeout,
+			"numberPointer":  &number,
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		m.SetStringers(tCase.Enabled)
+
+		output := string(m.JS())
+		for _, str := range tCase.Expected {
+			if !strings.Contains(output, str) {
+				t.Run(name, func(t *testing.T) {
+					t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+				})
+			}
+		}
+	}
+}
+
+// TestBigNumbers ensures that big.Int and big.Float values are
+// localized with all of their digits, as numbers or strings.
+func TestBigNumbers(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	ratio, _ := new(big.Float).SetString("1.5")
+	type account struct {
+		Balance big.Int `json:"balance"`
+	}
+
+	bigCases := map[string]struct {
+		Format   localize.BigFormat
+		Expected []string
+	}{
+		"number": {localize.BigFormatNumber, []string{
+			"\"huge\":123456789012345678901234567890,",
+			"\"ratio\":1.5,",
+			"\"account\":{\n\"balance\":9007199254740993,\n},",
+			"\"none\":null,",
+		}},
+		"string": {localize.BigFormatString, []string{
+			"\"huge\":\"123456789012345678901234567890\",",
+			"\"ratio\":\"1.5\",",
+			"\"account\":{\n\"balance\":\"9007199254740993\",\n},",
+			"\"none\":null,",
+		}},
+	}
+	for name, tCase := range bigCases {
+		acct := account{}
+		acct.Balance.SetInt64(1<<53 + 1)
+		m, err := localize.NewMap("bigCase", localize.Data{
+			"huge":    huge,
+			"ratio":   ratio,
+			"account": acct,
+			"none":    (*big.Int)(nil),
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetBigFormat(tCase.Format); nil != err {
+			t.Fatalf("Failed to set big number format,\nerr: %v\n", err)
+		}
+
+		output, err := m.JSWithError()
+		if nil != err {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", nil, err)
+			})
+		}
+		for _, str := range tCase.Expected {
+			if !strings.Contains(string(output), str) {
+				t.Run(name, func(t *testing.T) {
+					t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+				})
+			}
+		}
+	}
+
+	m, err := localize.NewMap("bigCase", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetBigFormat(localize.BigFormat(-1)); !errors.Is(err, localize.ErrInvalidBigFormat) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidBigFormat, err)
+	}
+}
+
+// TestFloatPolicies ensures that NaN and ±Inf are localized
+// according to the float policy.
+func TestFloatPolicies(t *testing.T) {
+	policyCases := map[string]struct {
+		Policy   localize.FloatPolicy
+		Strict   bool
+		Err      error
+		Expected []string
+	}{
+		"error": {localize.FloatPolicyError, false, localize.ErrNonFiniteFloat, []string{
+			"\"inf\":null,", "\"nan\":null,", "\"negInf\":null,", "\"small\":null,",
+		}},
+		"null": {localize.FloatPolicyNull, false, nil, []string{
+			"\"inf\":null,", "\"nan\":null,", "\"negInf\":null,", "\"small\":null,",
+		}},
+		"literal": {localize.FloatPolicyLiteral, false, nil, []string{
+			"\"inf\":Infinity,", "\"nan\":NaN,", "\"negInf\":-Infinity,", "\"small\":NaN,",
+		}},
+		"strictLiteral": {localize.FloatPolicyLiteral, true, localize.ErrNonFiniteFloat, []string{
+			"\"inf\":null,", "\"nan\":null,", "\"negInf\":null,", "\"small\":null\n",
+		}},
+	}
+	for name, tCase := range policyCases {
+		m, err := localize.NewMap("floatCase", localize.Data{
+			"inf":    math.Inf(1),
+			"nan":    math.NaN(),
+			"negInf": math.Inf(-1),
+			"small":  float32(math.NaN()),
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetFloatPolicy(tCase.Policy); nil != err {
+			t.Fatalf("Failed to set float policy,\nerr: %v\n", err)
+		}
+		m.SetStrict(tCase.Strict)
+
+		output, err := m.JSWithError()
+		if !errors.Is(err, tCase.Err) {
+			t.Run(name, func(t *testing.T) {
+				t.Errorf("Expected err: %v,\ngot: %v\n", tCase.Err, err)
+			})
+		}
+		for _, str := range tCase.Expected {
+			if !strings.Contains(string(output), str) {
+				t.Run(name, func(t *testing.T) {
+					t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+				})
+			}
+		}
+	}
+
+	m, err := localize.NewMap("floatCase", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetFloatPolicy(localize.FloatPolicy(-1)); !errors.Is(err, localize.ErrInvalidFloatPolicy) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidFloatPolicy, err)
+	}
+}
+
+// TestIntPolicies ensures that integers outside of JavaScript's
+// safe range are localized according to the integer policy.
+func TestIntPolicies(t *testing.T) {
+	policyCases := map[string]struct {
+		Policy   localize.IntPolicy
+		Expected []string
+	}{
+		"number": {localize.IntPolicyNumber, []string{
+			"\"above\":9007199254740993,",
+			"\"below\":-9007199254740993,",
+			"\"max\":18446744073709551615,",
+			"\"safe\":9007199254740991,",
+		}},
+		"string": {localize.IntPolicyString, []string{
+			"\"above\":\"9007199254740993\",",
+			"\"below\":\"-9007199254740993\",",
+			"\"max\":\"18446744073709551615\",",
+			"\"safe\":9007199254740991,",
+		}},
+		"bigInt": {localize.IntPolicyBigInt, []string{
+			"\"above\":9007199254740993n,",
+			"\"below\":-9007199254740993n,",
+			"\"max\":18446744073709551615n,",
+			"\"safe\":9007199254740991,",
+		}},
+	}
+	for name, tCase := range policyCases {
+		m, err := localize.NewMap("intPolicyCase", localize.Data{
+			"above": int64(1<<53 + 1),
+			"below": int64(-(1<<53 + 1)),
+			"max":   uint64(math.MaxUint64),
+			"safe":  int64(1<<53 - 1),
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		if err := m.SetIntPolicy(tCase.Policy); nil != err {
+			t.Fatalf("Failed to set integer policy,\nerr: %v\n", err)
+		}
+
+		output := string(m.JS())
+		for _, str := range tCase.Expected {
+			if !strings.Contains(output, str) {
+				t.Run(name, func(t *testing.T) {
+					t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+				})
+			}
+		}
+	}
+
+	m, err := localize.NewMap("intPolicyCase", localize.Data{})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	if err := m.SetIntPolicy(localize.IntPolicy(-1)); !errors.Is(err, localize.ErrInvalidIntPolicy) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrInvalidIntPolicy, err)
+	}
+}
+
+// TestBigIntStrict ensures that BigInt literals, which aren't
+// valid JSON, are kept out of strict output.
+func TestBigIntStrict(t *testing.T) {
+	m, err := localize.NewMap("bigIntCase", localize.Data{
+		"id": int64(1<<53 + 1),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	m.SetStrict(true)
+	if err := m.SetIntPolicy(localize.IntPolicyBigInt); localize.ErrIncompatibleOptions != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrIncompatibleOptions, err)
+	}
+
+	m.SetStrict(false)
+	if err := m.SetIntPolicy(localize.IntPolicyBigInt); nil != err {
+		t.Fatalf("Failed to set integer policy,\nerr: %v\n", err)
+	}
+	data, err := m.JSON()
+	if nil != err {
+		t.Fatalf("Failed to get JSON,\nerr: %v\n", err)
+	}
+	if expected := "{\n\"id\":\"9007199254740993\"\n}"; expected != string(data) {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, data)
+	}
+}
+
+// letter is a named rune type, which isn't affected by the rune
+// option.
+type letter rune
+
+// TestRunes ensures that runes are localized as characters only
+// when enabled.
+func TestRunes(t *testing.T) {
+	runeCases := map[string]struct {
+		Enabled  bool
+		Expected []string
+	}{
+		"numbers":    {false, []string{"\"initial\":65,", "\"runes\":[71,111,],", "\"letter\":66,"}},
+		"characters": {true, []string{"\"initial\":\"A\",", "\"runes\":[\"G\",\"o\",],", "\"letter\":66,"}},
+	}
+	for name, tCase := range runeCases {
+		m, err := localize.NewMap("runeCase", localize.Data{
+			"initial": 'A',
+			"runes":   []rune("Go"),
+			"letter":  letter('B'),
+		})
+		if nil != err {
+			t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+		}
+		m.SetRunes(tCase.Enabled)
+		if tCase.Enabled != m.GetRunes() {
+			t.Errorf("Expected: %v,\ngot: %v\n", tCase.Enabled, m.GetRunes())
+		}
+
+		output := string(m.JS())
+		for _, str := range tCase.Expected {
+			if !strings.Contains(output, str) {
+				t.Run(name, func(t *testing.T) {
+					t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+				})
+			}
+		}
+	}
+}
+
+// money is an amount of cents, rendered by a custom handler.
+type money int64
+
+// TestRegisterHandler ensures that registered handlers take
+// precedence over the built-in translation.
+func TestRegisterHandler(t *testing.T) {
+	m, err := localize.NewMap("handlerCase", localize.Data{
+		"price":  money(123),
+		"prices": []money{5, 1000},
+		"count":  int64(123),
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.RegisterHandler(reflect.TypeOf(money(0)), func(v reflect.Value) (string, error) {
+		cents := v.Int()
+		return fmt.Sprintf("%q", fmt.Sprintf("$%d.%02d", cents/100, cents%100)), nil
+	})
+
+	output := string(m.JS())
+	expected := []string{
+		"\"price\":\"$1.23\",",
+		"\"prices\":[\"$0.05\",\"$10.00\",],",
+		"\"count\":123,",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+
+	// Handler errors are reported, and the value is replaced.
+	errHandler := errors.New("Handler failed")
+	m.RegisterHandler(reflect.TypeOf(money(0)), func(v reflect.Value) (string, error) {
+		return "", errHandler
+	})
+	js, err := m.JSWithError()
+	if !errors.Is(err, errHandler) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", errHandler, err)
+	}
+	if str := "\"price\":null,"; !strings.Contains(string(js), str) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, js)
+	}
+
+	// Removing the handler restores the built-in translation.
+	m.RegisterHandler(reflect.TypeOf(money(0)), nil)
+	if str := "\"price\":123,"; !strings.Contains(string(m.JS()), str) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, m.JS())
+	}
+}
+
+// TestSetKeyRenderer ensures that key renderers take precedence
+// over the built-in translation for top-level keys only.
+func TestSetKeyRenderer(t *testing.T) {
+	m, err := localize.NewMap("rendererCase", localize.Data{
+		"widget": "ignored",
+		"nested": map[string]string{"widget": "kept"},
+		"count":  123,
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+	m.SetKeyRenderer("widget", func(v interface{}) (template.JS, error) {
+		return "new Widget()", nil
+	})
+
+	output := string(m.JS())
+	expected := []string{
+		"\"widget\":new Widget(),",
+		"\"nested\":{\n\"widget\":\"kept\",\n},",
+		"\"count\":123,",
+	}
+	for _, str := range expected {
+		if !strings.Contains(output, str) {
+			t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, output)
+		}
+	}
+
+	// Renderer errors are reported, and the value is replaced.
+	errRenderer := errors.New("Renderer failed")
+	m.SetKeyRenderer("widget", func(v interface{}) (template.JS, error) {
+		return "", errRenderer
+	})
+	js, err := m.JSWithError()
+	if !errors.Is(err, errRenderer) {
+		t.Errorf("Expected err: %v,\ngot: %v\n", errRenderer, err)
+	}
+	if str := "\"widget\":null,"; !strings.Contains(string(js), str) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, js)
+	}
+
+	// Removing the renderer restores the built-in translation.
+	m.SetKeyRenderer("widget", nil)
+	if str := "\"widget\":\"ignored\","; !strings.Contains(string(m.JS()), str) {
+		t.Errorf("Expected output to contain: %q,\ngot: %q\n", str, m.JS())
+	}
+}
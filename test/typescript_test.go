@@ -0,0 +1,116 @@
+/**
+ * typescript_test.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/foresthoffman/localize"
+)
+
+// TestTypeScriptDecl ensures that the declared type of the global
+// variable is inferred from scalar values.
+func TestTypeScriptDecl(t *testing.T) {
+	deleted := time.Unix(0, 0)
+	m, err := localize.NewMap("_localData", localize.Data{
+		"deleted": &deleted,
+		"motd":    "Hello world!",
+		"count":   3,
+		"ratio":   0.5,
+		"debug":   false,
+		"expires": time.Unix(0, 0),
+		"ids":     []int{1, 2},
+		"mixed":   []interface{}{1, "two"},
+		"none":    nil,
+	}, localize.WithDeclaration(localize.DeclarationConst))
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	expected := `declare const _localData: {
+	"count": number;
+	"debug": boolean;
+	"deleted": string;
+	"expires": string;
+	"ids": number[];
+	"mixed": (number | string)[];
+	"motd": string;
+	"none": null;
+	"ratio": number;
+};
+`
+	decl, err := m.TypeScriptDecl()
+	if nil != err {
+		t.Fatalf("Failed to generate declaration,\nerr: %v\n", err)
+	}
+	if expected != decl {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, decl)
+	}
+
+	// Pointers to times follow the time format too.
+	if err := m.SetTimeFormat(localize.TimeFormatUnixMilli, ""); nil != err {
+		t.Fatalf("Failed to set time format,\nerr: %v\n", err)
+	}
+	decl, err = m.TypeScriptDecl()
+	if nil != err {
+		t.Fatalf("Failed to generate declaration,\nerr: %v\n", err)
+	}
+	if str := "\"deleted\": number;"; !strings.Contains(decl, str) {
+		t.Errorf("Expected declaration to contain: %q,\ngot: %q\n", str, decl)
+	}
+}
+
+// TestTypeScriptDeclNested ensures that nested maps and structs
+// are declared as nested object types.
+func TestTypeScriptDeclNested(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	m, err := localize.NewMap("_localData", localize.Data{
+		"nonce": map[string]string{"login": "abc"},
+		"users": []user{{1, "Ada"}},
+		"empty": []user{},
+	})
+	if nil != err {
+		t.Fatalf("Failed to create new map,\nerr: %v\n", err)
+	}
+
+	expected := `declare var _localData: {
+	"empty": {
+		"id": number;
+		"name": string;
+	}[];
+	"nonce": {
+		"login": string;
+	};
+	"users": {
+		"id": number;
+		"name": string;
+	}[];
+};
+`
+	decl, err := m.TypeScriptDecl()
+	if nil != err {
+		t.Fatalf("Failed to generate declaration,\nerr: %v\n", err)
+	}
+	if expected != decl {
+		t.Errorf("Expected: %q,\ngot: %q\n", expected, decl)
+	}
+
+	// Callbacks have nothing to declare.
+	if err := m.SetCallback("myCallback"); nil != err {
+		t.Fatalf("Failed to set callback,\nerr: %v\n", err)
+	}
+	if _, err := m.TypeScriptDecl(); localize.ErrIncompatibleOptions != err {
+		t.Errorf("Expected err: %v,\ngot: %v\n", localize.ErrIncompatibleOptions, err)
+	}
+}
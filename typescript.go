@@ -0,0 +1,237 @@
+/**
+ * typescript.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// TypeScriptDecl generates a TypeScript declaration of the global
+// variable, with its type inferred from the data, which can be
+// saved as a .d.ts file for front-end code. Strings, numbers, and
+// booleans become the matching primitive types, and maps and
+// structs become inline object types, keyed in the same way as in
+// the output. Arrays are typed by their elements, with a union of
+// the element types when they differ. The options that change
+// how values are localized, such as SetTimeFormat or
+// SetIntPolicy, are taken into account, while json.Marshaler
+// values and values rendered by handlers or key renderers are
+// typed as unknown.
+//
+// The declaration follows the declaration keyword, e.g.
+// `declare const _localData: {...};`. The data of a global object
+// property is declared as a global var, which browsers make a
+// property of the global object. Dotted global names, bracketed
+// global names, and callbacks have no declaration, and return
+// ErrIncompatibleOptions. Data that couldn't be localized is
+// reported in the same way as by JSWithError.
+func (l *Map) TypeScriptDecl() (string, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if "" != l.callback || l.bracketed || strings.Contains(l.globalName, ".") {
+		return "", ErrIncompatibleOptions
+	}
+
+	e := l.newEncoder(io.Discard)
+	dataType := e.tsObject(e.mapEntries(reflect.ValueOf(l.data)), l.keyRenderers, 0)
+	if err := e.error(); nil != err {
+		return "", err
+	}
+
+	var decl string
+	switch l.declaration {
+	case DeclarationExportDefault:
+		decl = "declare const _default: " + dataType + ";\nexport default _default;"
+	case DeclarationCommonJS:
+		decl = "declare const _exports: " + dataType + ";\nexport = _exports;"
+	case DeclarationExportConst:
+		decl = "export declare const " + l.globalName + ": " + dataType + ";"
+	case DeclarationLet, DeclarationConst:
+		decl = "declare " + l.declaration.keyword() + " " + l.globalName + ": " + dataType + ";"
+	default:
+		decl = "declare var " + l.globalName + ": " + dataType + ";"
+	}
+	return decl + "\n", nil
+}
+
+// tsObject formats fields or map entries as an inline object
+// type, indented by depth tabs. The fields named by renderers
+// are typed as unknown.
+func (e *encoder) tsObject(fields []field, renderers map[string]KeyRenderer, depth int) string {
+	if 0 == len(fields) {
+		return "{}"
+	}
+
+	indent := strings.Repeat("\t", depth)
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, f := range fields {
+		e.push(f.name)
+		fieldType := "unknown"
+		if _, ok := renderers[f.name]; !ok {
+			fieldType = e.tsType(f.value, depth+1)
+			if f.quoted {
+				fieldType = "string"
+			}
+		}
+		e.pop()
+		b.WriteString(indent + "\t" + quote(f.name) + ": " + fieldType + ";\n")
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// tsType infers the TypeScript type of the target, mirroring how
+// reflect localizes it. Nested object types are indented by depth
+// tabs.
+func (e *encoder) tsType(target reflect.Value, depth int) string {
+	if !target.IsValid() {
+		return "null"
+	}
+	if _, ok := e.handlers[target.Type()]; ok {
+		return "unknown"
+	}
+
+	unwrapped := target
+	if reflect.Ptr == unwrapped.Kind() && !unwrapped.IsNil() {
+		unwrapped = unwrapped.Elem()
+	}
+
+	switch {
+	case timeType == unwrapped.Type():
+		if TimeFormatUnixMilli == e.timeFormat {
+			return "number"
+		}
+		return "string"
+	case durationType == unwrapped.Type():
+		if DurationFormatString == e.durationFormat {
+			return "string"
+		}
+		return "number"
+	case numberType == unwrapped.Type():
+		return "number"
+	case bigIntType == unwrapped.Type() || bigFloatType == unwrapped.Type():
+		if BigFormatString == e.bigFormat {
+			return "string"
+		}
+		return "number"
+	case isMarshaler(target):
+		return "unknown"
+	case isTextMarshaler(target) || urlType == target.Type():
+		return "string"
+	case e.stringers && isStringer(target):
+		return "string"
+	case e.runes && runeType == target.Type():
+		return "string"
+	}
+
+	switch target.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if target.IsNil() {
+			return "null"
+		}
+		if reflect.Ptr == target.Kind() {
+			if !e.enter(target) {
+				return "null"
+			}
+			defer e.leave(target)
+		}
+		return e.tsType(target.Elem(), depth)
+	case reflect.Struct:
+		return e.tsObject(structFields(target, e.keyCase), nil, depth)
+	case reflect.Map:
+		if !target.IsNil() {
+			if !e.enter(target) {
+				return "null"
+			}
+			defer e.leave(target)
+		}
+		return e.tsObject(e.mapEntries(target), nil, depth)
+	case reflect.Slice, reflect.Array:
+		if reflect.Slice == target.Kind() && reflect.Uint8 == target.Type().Elem().Kind() {
+			return "string"
+		}
+		if reflect.Slice == target.Kind() && 0 < target.Len() {
+			if !e.enter(target) {
+				return "null"
+			}
+			defer e.leave(target)
+		}
+		return e.tsArray(target, depth)
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := target.Int()
+		return e.tsInt(-maxSafeInt <= i && i <= maxSafeInt)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.tsInt(target.Uint() <= maxSafeInt)
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Complex64, reflect.Complex128:
+		return "[number, number]"
+	case reflect.String:
+		return "string"
+	}
+
+	e.fail(fmt.Errorf(
+		"%w, %v, at key, %v",
+		ErrUnsupportedKind,
+		target.Kind(),
+		e.keyPath(),
+	))
+	return "null"
+}
+
+// tsInt infers the TypeScript type of an integer under the
+// integer policy.
+func (e *encoder) tsInt(safe bool) string {
+	switch {
+	case safe || IntPolicyNumber == e.intPolicy:
+		return "number"
+	case IntPolicyBigInt == e.intPolicy && !e.strict:
+		return "bigint"
+	}
+	return "string"
+}
+
+// tsArray infers the TypeScript type of a slice or array, as a
+// union of the distinct types of its elements. The type of an
+// empty one is inferred from its element type, where possible.
+func (e *encoder) tsArray(target reflect.Value, depth int) string {
+	var types []string
+	seen := make(map[string]bool)
+	for i := 0; i < target.Len(); i++ {
+		e.pushIndex(i)
+		elemType := e.tsType(target.Index(i), depth)
+		e.pop()
+		if !seen[elemType] {
+			seen[elemType] = true
+			types = append(types, elemType)
+		}
+	}
+	if 0 == len(types) {
+		elem := target.Type().Elem()
+		for reflect.Ptr == elem.Kind() {
+			elem = elem.Elem()
+		}
+		if reflect.Interface == elem.Kind() {
+			return "unknown[]"
+		}
+		types = append(types, e.tsType(reflect.Zero(elem), depth))
+	}
+
+	if 1 == len(types) {
+		return types[0] + "[]"
+	}
+	return "(" + strings.Join(types, " | ") + ")[]"
+}
@@ -0,0 +1,261 @@
+/**
+ * verify.go
+ *
+ * Copyright (c) 2017-2019 Forest Hoffman. All Rights Reserved.
+ * License: MIT License (see the included LICENSE file) or download at
+ *     https://raw.githubusercontent.com/foresthoffman/localize/master/LICENSE
+ */
+
+package localize
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+)
+
+// ErrVerification indicates that the localized data didn't come
+// back out the same as it went in.
+var ErrVerification = fmt.Errorf("Failed to verify localized data")
+
+// Verify is a debugging aid, which checks that the localized data
+// survives a round trip. The data is serialized as strict JSON,
+// parsed again, and compared against a reference built from the
+// data by the rules of encoding/json, except where the library
+// documents otherwise: nil maps and slices are empty, complex
+// numbers are [real, imag] arrays, url.URL values are strings,
+// and interface map keys are converted by their concrete value.
+// Options that deliberately change the representation of values,
+// such as SetStringers or SetTimeFormat, and registered handlers
+// are reported as mismatches too. The returned errors wrap
+// ErrVerification, and name the first key that doesn't match.
+func (l *Map) Verify() error {
+	output, err := l.JSON()
+	if nil != err {
+		return fmt.Errorf("%w: %w", ErrVerification, err)
+	}
+	got, err := decodeJSON(output)
+	if nil != err {
+		return fmt.Errorf("%w: output isn't valid JSON: %w", ErrVerification, err)
+	}
+
+	l.mu.RLock()
+	expected, err := reference(reflect.ValueOf(l.data))
+	l.mu.RUnlock()
+	if nil != err {
+		return fmt.Errorf("%w: %w", ErrVerification, err)
+	}
+
+	if path, ok := compareJSON(expected, got, ""); !ok {
+		return fmt.Errorf("%w: mismatch at key, %q", ErrVerification, path)
+	}
+	return nil
+}
+
+// Validate checks that the data can be localized, without
+// keeping any of the output, so that problems can be caught
+// before the map is committed to a response. The keys of the data
+// map are validated in the same way as by Add, and then the data
+// is walked with the map's options, catching unsupported kinds,
+// cyclic data, and anything else that JSWithError would report.
+// The first problem found is returned.
+func (l *Map) Validate() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if err := l.validateData(l.data); nil != err {
+		return err
+	}
+	e := l.newEncoder(io.Discard)
+	e.reflect(reflect.ValueOf(l.data))
+	return e.error()
+}
+
+// DataEqual reports whether the localization map's data is equal
+// to the other data, which gives tests a stable way to check the
+// content of a map, regardless of formatting or key order. Both
+// are localized as strict JSON with the map's options and then
+// compared, so values that localize the same are equal, e.g. the
+// int 1 and the float64 1.0. Data that can't be localized is
+// never equal.
+func (l *Map) DataEqual(other Data) bool {
+	output, err := l.JSON()
+	if nil != err {
+		return false
+	}
+
+	l.mu.RLock()
+	var buf bytes.Buffer
+	e := l.newEncoder(&buf)
+	e.strict = true
+	if nil == other {
+		other = Data{}
+	}
+	e.reflect(reflect.ValueOf(other))
+	l.mu.RUnlock()
+	if nil != e.error() {
+		return false
+	}
+
+	got, err := decodeJSON(output)
+	if nil != err {
+		return false
+	}
+	expected, err := decodeJSON(buf.Bytes())
+	if nil != err {
+		return false
+	}
+	_, ok := compareJSON(expected, got, "")
+	return ok
+}
+
+// reference builds the decoded JSON that the target is expected
+// to localize to, as described by Verify. Values that aren't
+// containers, and those that marshal themselves, are encoded by
+// encoding/json.
+func reference(target reflect.Value) (interface{}, error) {
+	if !target.IsValid() {
+		return nil, nil
+	}
+
+	marshals := target.Type().Implements(marshalerType) ||
+		target.Type().Implements(textMarshalerType)
+	switch {
+	case marshals:
+	case urlType == target.Type():
+		u := target.Interface().(url.URL)
+		return u.String(), nil
+	case reflect.Interface == target.Kind() || reflect.Ptr == target.Kind():
+		if target.IsNil() {
+			return nil, nil
+		}
+		return reference(target.Elem())
+	case reflect.Struct == target.Kind():
+		fields := structFields(target, KeyCaseNone)
+		object := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			value := f.value
+			if q, ok := quotedString(value, quote); ok && f.quoted {
+				value = reflect.ValueOf(q)
+			}
+			v, err := reference(value)
+			if nil != err {
+				return nil, err
+			}
+			object[f.name] = v
+		}
+		return object, nil
+	case reflect.Map == target.Kind():
+		object := make(map[string]interface{}, target.Len())
+		iter := target.MapRange()
+		for iter.Next() {
+			keyValue := iter.Key()
+			if reflect.Interface == keyValue.Kind() && !keyValue.IsNil() {
+				keyValue = keyValue.Elem()
+			}
+			key, ok := mapKey(keyValue)
+			if !ok {
+				return nil, fmt.Errorf("%w of map key, %v", ErrUnsupportedKind, keyValue.Kind())
+			}
+			v, err := reference(iter.Value())
+			if nil != err {
+				return nil, err
+			}
+			object[key] = v
+		}
+		return object, nil
+	case reflect.Slice == target.Kind() && reflect.Uint8 == target.Type().Elem().Kind():
+	case reflect.Slice == target.Kind() || reflect.Array == target.Kind():
+		array := make([]interface{}, target.Len())
+		for i := range array {
+			v, err := reference(target.Index(i))
+			if nil != err {
+				return nil, err
+			}
+			array[i] = v
+		}
+		return array, nil
+	case reflect.Complex64 == target.Kind():
+		c := target.Complex()
+		return reference(reflect.ValueOf([2]float32{float32(real(c)), float32(imag(c))}))
+	case reflect.Complex128 == target.Kind():
+		c := target.Complex()
+		return reference(reflect.ValueOf([2]float64{real(c), imag(c)}))
+	}
+
+	b, err := json.Marshal(target.Interface())
+	if nil != err {
+		return nil, fmt.Errorf("data can't be encoded as JSON: %w", err)
+	}
+	return decodeJSON(b)
+}
+
+// decodeJSON parses the JSON, keeping numbers as they're written
+// so that no precision is lost in the comparison.
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); nil != err {
+		return nil, err
+	}
+	return v, nil
+}
+
+// compareJSON compares two decoded JSON values, and reports the
+// path of the first difference, e.g. "nonce.login" or
+// "items[2]".
+func compareJSON(expected, got interface{}, path string) (string, bool) {
+	switch expected := expected.(type) {
+	case map[string]interface{}:
+		gotMap, ok := got.(map[string]interface{})
+		if !ok {
+			return path, false
+		}
+		keys := make([]string, 0, len(expected)+len(gotMap))
+		for key := range expected {
+			keys = append(keys, key)
+		}
+		for key := range gotMap {
+			if _, ok := expected[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := key
+			if "" != path {
+				keyPath = path + "." + key
+			}
+			expectedVal, inExpected := expected[key]
+			gotVal, inGot := gotMap[key]
+			if inExpected != inGot {
+				return keyPath, false
+			}
+			if p, ok := compareJSON(expectedVal, gotVal, keyPath); !ok {
+				return p, false
+			}
+		}
+		return "", true
+	case []interface{}:
+		gotSlice, ok := got.([]interface{})
+		if !ok || len(expected) != len(gotSlice) {
+			return path, false
+		}
+		for i := range expected {
+			if p, ok := compareJSON(expected[i], gotSlice[i], fmt.Sprintf("%s[%d]", path, i)); !ok {
+				return p, false
+			}
+		}
+		return "", true
+	}
+	if !reflect.DeepEqual(expected, got) {
+		return path, false
+	}
+	return "", true
+}